@@ -0,0 +1,101 @@
+package df
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromCSV creates a DataFrame from CSV data whose first record is the column names.
+// The type of each column is inferred from its values: int, float64, bool for "true" and "false" in any case,
+// time.Time for RFC 3339 times, or string.
+// An empty cell in a column which is not a string column is a null value.
+func FromCSV(r io.Reader) (DataFrame, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("csv has no header")
+	}
+
+	header, rows := records[0], records[1:]
+	columns := make([]Series, len(header))
+	for j, name := range header {
+		cells := make([]string, len(rows))
+		for i, row := range rows {
+			cells[i] = row[j]
+		}
+		columns[j] = &series{name: name, data: parseCells(cells)}
+	}
+	return NewDataFrame(columns...), nil
+}
+
+// parseCells converts the cells of a column to the first type which all non-empty cells can be parsed as.
+func parseCells(cells []string) []any {
+	parsers := []func(string) (any, error){
+		func(s string) (any, error) { return strconv.Atoi(s) },
+		func(s string) (any, error) { return strconv.ParseFloat(s, 64) },
+		func(s string) (any, error) {
+			if !strings.EqualFold(s, "true") && !strings.EqualFold(s, "false") {
+				return nil, fmt.Errorf("invalid bool: %q", s)
+			}
+			return strings.EqualFold(s, "true"), nil
+		},
+		func(s string) (any, error) { return time.Parse(time.RFC3339, s) },
+	}
+
+	for _, parse := range parsers {
+		data, ok := parseAll(cells, parse)
+		if ok {
+			return data
+		}
+	}
+	return AsAny(cells)
+}
+
+// parseAll parses every non-empty cell, and the empty cells become null values.
+// It fails if a cell can not be parsed, or all cells are empty.
+func parseAll(cells []string, parse func(string) (any, error)) ([]any, bool) {
+	data := make([]any, len(cells))
+	found := false
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		v, err := parse(cell)
+		if err != nil {
+			return nil, false
+		}
+		data[i], found = v, true
+	}
+	return data, found
+}
+
+// writeCSV writes the DataFrame as CSV data which FromCSV reads back: the first record is the column names,
+// a null value is an empty cell and a time is in RFC 3339.
+func writeCSV(w io.Writer, d DataFrame) error {
+	cw := csv.NewWriter(w)
+	cw.Write(d.Columns())
+	for i := 0; i < d.Rows(); i++ {
+		record := make([]string, len(d.Columns()))
+		for j, col := range d.Columns() {
+			switch v := d.GetColumn(col).Data()[i].(type) {
+			case nil:
+			case time.Time:
+				record[j] = v.Format(time.RFC3339)
+			case float64:
+				record[j] = strconv.FormatFloat(v, 'g', -1, 64)
+			default:
+				record[j] = fmt.Sprint(v)
+			}
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
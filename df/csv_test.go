@@ -0,0 +1,75 @@
+package df
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromCSV(t *testing.T) {
+	data := "id,price,ok,day,name,empty\n" +
+		"1,1.5,true,2024-01-01T00:00:00Z,a,\n" +
+		"2,,FALSE,,,\n" +
+		",3,,2024-01-03T08:00:00+08:00,c d,\n"
+	d, err := FromCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.Columns(), []string{"id", "price", "ok", "day", "name", "empty"}; !slices.Equal(got, want) {
+		t.Fatalf("got columns %v, want %v", got, want)
+	}
+	day := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		want []any
+	}{
+		{"id", []any{1, 2, nil}},
+		// An int is a float64 in a column of floats
+		{"price", []any{1.5, nil, 3.0}},
+		{"ok", []any{true, false, nil}},
+		{"day", []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), nil, day}},
+		// An empty cell is an empty string in a string column
+		{"name", []any{"a", "", "c d"}},
+		{"empty", []any{"", "", ""}},
+	} {
+		got := d.GetColumn(tc.name).Data()
+		if !slices.EqualFunc(got, tc.want, func(a, b any) bool {
+			if t, ok := a.(time.Time); ok {
+				return b != nil && t.Equal(b.(time.Time))
+			}
+			return a == b
+		}) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// A column of mixed values is a string column
+	d, err = FromCSV(strings.NewReader("a\n1\ntrue\n2.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.GetColumn("a").Data(), []any{"1", "true", "2.5"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Only a header is a DataFrame without rows
+	d, err = FromCSV(strings.NewReader("a,b\n"))
+	if err != nil || d.Rows() != 0 || !slices.Equal(d.Columns(), []string{"a", "b"}) {
+		t.Errorf("got %v %v, want the empty columns a and b", d, err)
+	}
+}
+
+func TestFromCSVErrors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		// Ragged rows
+		"a,b\n1\n",
+		"a,b\n1,2,3\n",
+		"a\n\"1\n",
+	} {
+		if _, err := FromCSV(strings.NewReader(data)); err == nil {
+			t.Errorf("%q: got no error", data)
+		}
+	}
+}
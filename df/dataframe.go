@@ -2,16 +2,20 @@ package df
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"reflect"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/discoverkl/goterm/df/vs"
 )
 
 // SupportedType constrains the types that can be used in a Series
 type SupportedType interface {
-	~string | ~float64 | ~int
+	~string | ~float64 | ~int | ~bool | time.Time
 }
 
 type Series interface {
@@ -21,10 +25,43 @@ type Series interface {
 	Name() string
 	Data() []any
 	ToFloat64() []float64
+	// The As methods return the values of a series of their type, and panic for another type.
+	// A null value is NaN for AsFloat64, and the zero value of the type for the others.
 	AsFloat64() []float64
 	AsInt() []int
 	AsString() []string
+	AsTime() []time.Time
+	AsBool() []bool
 	Avg() Series
+	Quantile(q float64) Series
+	Var() Series
+	Std() Series
+	Cumsum() Series
+	Cumprod() Series
+	MinMaxScale() Series
+	ZScore() Series
+	RollingMean(window int) Series
+	Shift(n int) Series
+	// Copy returns a copy of the series, which doesn't share its data with the series.
+	Copy() Series
+
+	// Gt returns a boolean series which tells whether each value is greater than v.
+	Gt(v float64) Series
+	// Lt returns a boolean series which tells whether each value is less than v.
+	Lt(v float64) Series
+	// Eq returns a boolean series which tells whether each value equals v.
+	Eq(v any) Series
+
+	// Element-wise arithmetic, which returns a float64 series. A null or non-numeric value results in NaN.
+	// The series operators panic if the lengths do not match.
+	Add(other Series) Series
+	Sub(other Series) Series
+	Mul(other Series) Series
+	Div(other Series) Series
+	AddScalar(v float64) Series
+	SubScalar(v float64) Series
+	MulScalar(v float64) Series
+	DivScalar(v float64) Series
 }
 
 // Concrete implementation for Series
@@ -45,61 +82,108 @@ func (s *series) Data() []any {
 	return s.data
 }
 
+func (s *series) Copy() Series {
+	return &series{name: s.name, data: slices.Clone(s.data)}
+}
+
+// ToFloat64 converts the values to float64, a null value is NaN.
 func (s *series) ToFloat64() []float64 {
 	size := len(s.data)
 	if size == 0 {
 		return []float64{}
 	}
-	switch s.data[0].(type) {
+	var convert func(v any) float64
+	switch firstValue(s.data).(type) {
 	case float64:
-		return Map(s.data, func(v any) float64 {
-			return float64(v.(float64))
-		})
+		convert = func(v any) float64 { return v.(float64) }
 	case int:
-		return Map(s.data, func(v any) float64 {
-			return float64(v.(int))
-		})
+		convert = func(v any) float64 { return float64(v.(int)) }
 	case string:
 		return slices.Collect(vs.IntRange(0, size-1))
+	case time.Time:
+		// Unix seconds
+		convert = func(v any) float64 { return float64(v.(time.Time).UnixNano()) / 1e9 }
+	case bool:
+		convert = func(v any) float64 {
+			if v.(bool) {
+				return 1
+			}
+			return 0
+		}
 	default:
 		return make([]float64, size)
 	}
+	return Map(s.data, func(v any) float64 {
+		if v == nil {
+			return math.NaN()
+		}
+		return convert(v)
+	})
 }
 
+// AsFloat64 returns the values of a float64 series, a null value is NaN.
 func (s *series) AsFloat64() []float64 {
-	return Map(s.data, func(v any) float64 {
-		return v.(float64)
-	})
+	return asType(s.data, math.NaN())
 }
 
+// AsInt returns the values of an int series, a null value is 0.
 func (s *series) AsInt() []int {
-	return Map(s.data, func(v any) int {
-		return v.(int)
-	})
+	return asType(s.data, 0)
 }
 
+// AsString returns the values of a string series, a null value is "".
 func (s *series) AsString() []string {
-	return Map(s.data, func(v any) string {
-		return v.(string)
+	return asType(s.data, "")
+}
+
+// AsTime returns the values of a time series, a null value is the zero time.
+func (s *series) AsTime() []time.Time {
+	return asType(s.data, time.Time{})
+}
+
+// AsBool returns the values of a bool series, a null value is false.
+func (s *series) AsBool() []bool {
+	return asType(s.data, false)
+}
+
+// asType returns the values of data as T, where null is the null value. It panics for a value of another type.
+func asType[T any](data []any, null T) []T {
+	return Map(data, func(v any) T {
+		if v == nil {
+			return null
+		}
+		return v.(T)
 	})
 }
 
+// Avg returns a series with the average of the values, null and NaN values are ignored.
+// It is NaN if there is no value, and the average of a time series is a time.
 func (s *series) Avg() Series {
 	if len(s.data) == 0 {
 		return NewSeries("avg", []float64{})
 	}
-	var avg float64
-	switch s.data[0].(type) {
-	case float64:
-		avg = Avg(s.AsFloat64())
-	case int:
-		avg = Avg(s.AsInt())
+	switch v := firstValue(s.data).(type) {
+	case float64, int, bool:
+		// The fraction of true values for bool
 	case string:
 		return NewSeries(s.name, []string{"Avg"})
+	case time.Time:
+		sec := avgValues(s.values())
+		return NewTimeSeries(s.name, []time.Time{time.Unix(0, int64(sec*1e9)).In(v.Location())})
+	case nil:
+		return NewSeries(s.name, []float64{math.NaN()})
 	default:
 		panic("unsupported")
 	}
-	return NewSeries(s.name, []float64{avg})
+	return NewSeries(s.name, []float64{avgValues(s.values())})
+}
+
+// avgValues returns the average of values, or NaN if there is no value.
+func avgValues(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return Avg(values)
 }
 
 func (s *series) String() string {
@@ -120,17 +204,29 @@ func NewSeries[T SupportedType](name string, data []T) Series {
 	}
 }
 
+// NewTimeSeries creates a Series of times, which are shown in RFC 3339 format and plotted on a time axis.
+func NewTimeSeries(name string, data []time.Time) Series {
+	return NewSeries(name, data)
+}
+
+// NewBoolSeries creates a Series of booleans, which can be used as a mask to filter rows, see DataFrame.FilterMask.
+func NewBoolSeries(name string, data []bool) Series {
+	return NewSeries(name, data)
+}
+
+// NewSeriesAny creates a Series of values of one of the supported types, where nil is a null value.
+// The data is copied, so changing the slice later doesn't change the series.
 func NewSeriesAny(name string, data []any) Series {
-	if len(data) > 0 {
-		switch data[0].(type) {
-		case float64, int, string:
+	if v := firstValue(data); v != nil {
+		switch v.(type) {
+		case float64, int, string, bool, time.Time:
 		default:
 			panic("unsupported")
 		}
 	}
 	return &series{
 		name: name,
-		data: data,
+		data: slices.Clone(data),
 	}
 }
 
@@ -210,15 +306,28 @@ type DataFrame interface {
 	RemoveColumn(name string) error
 	RemoveColumnAt(index int) error
 
+	// Copy returns a copy of the DataFrame, which doesn't share its columns and their data with the DataFrame.
+	Copy() DataFrame
+	// Head and Tail return a new DataFrame with a copy of the first or the last n rows.
 	Head(n int) DataFrame
 	Tail(n int) DataFrame
 	Avg() DataFrame
+	Resample(timeCol string, freq string, agg map[string]string) DataFrame
+	FilterMask(mask Series) DataFrame
+	Shift(n int, columns ...string) DataFrame
+	Merge(other DataFrame, opts MergeOptions) DataFrame
 
-	// Plot(options ...ChartOption)
+	// HTML renders the DataFrame as a table, so that it can be used as a term.BlockElement.
+	HTML() string
+
+	// Plot draws the DataFrame with a chart type which fits the data, see the Kind option to override it.
+	Plot(options ...ChartOption)
 	Bar(options ...ChartOption)
 	Line(options ...ChartOption)
 	Pie(options ...ChartOption)
+	Combo(kinds map[string]string, options ...ChartOption)
 	XY(options ...ChartOption)
+	RegressionPlot(xcol, ycol string, options ...ChartOption)
 }
 
 // Concrete implementation for DataFrame
@@ -286,6 +395,8 @@ func (df *dataFrame) GetColumnAt(index int) Series {
 	return nil
 }
 
+// SetColumn adds the series as a column, or replaces the column with the same name.
+// The DataFrame keeps the series itself, so add a Series.Copy if the column should not share its data.
 func (df *dataFrame) SetColumn(data Series) error {
 	name := data.Name()
 	df.columns[name] = data
@@ -326,30 +437,31 @@ func (df *dataFrame) RemoveColumnAt(index int) error {
 	return nil
 }
 
-func (df *dataFrame) Head(n int) DataFrame {
-	if n >= df.Rows() {
-		return df
-	}
-
-	// Create a new DataFrame with the first n rows of each column
+func (df *dataFrame) Copy() DataFrame {
 	columns := []Series{}
 	for _, colName := range df.order {
-		s := df.GetColumn(colName)
-		columns = append(columns, NewSeriesAny(colName, s.Data()[:n]))
+		columns = append(columns, df.GetColumn(colName).Copy())
 	}
 	return NewDataFrame(columns...)
 }
 
-func (df *dataFrame) Tail(n int) DataFrame {
-	if n >= df.Rows() {
-		return df
-	}
+func (df *dataFrame) Head(n int) DataFrame {
+	// Create a new DataFrame with the first n rows of each column
+	return df.slice(0, min(max(n, 0), df.Rows()))
+}
 
+func (df *dataFrame) Tail(n int) DataFrame {
 	// Create a new DataFrame with the last n rows of each column
+	return df.slice(max(df.Rows()-max(n, 0), 0), df.Rows())
+}
+
+// slice returns a new DataFrame with a copy of the rows in [i, j) of each column,
+// so that changing the data of one DataFrame doesn't change the other.
+func (df *dataFrame) slice(i, j int) DataFrame {
 	columns := []Series{}
 	for _, colName := range df.order {
 		s := df.GetColumn(colName)
-		columns = append(columns, NewSeriesAny(colName, s.Data()[df.Rows()-n:]))
+		columns = append(columns, &series{name: colName, data: slices.Clone(s.Data()[i:j])})
 	}
 	return NewDataFrame(columns...)
 }
@@ -365,6 +477,12 @@ func (df *dataFrame) Avg() DataFrame {
 
 // String returns a string representation of the DataFrame
 func (df *dataFrame) String() string {
+	return tableText(df, false, 0)
+}
+
+// tableText formats the DataFrame as aligned columns. The header is followed by a separator line
+// if separator is true, and only the first maxRows rows are shown if maxRows is positive.
+func tableText(df DataFrame, separator bool, maxRows int) string {
 	data := [][]string{}
 
 	// Add the column names as the first row
@@ -374,32 +492,21 @@ func (df *dataFrame) String() string {
 		return "<empty DataFrame>"
 	}
 
-	// get first row
-	row := []any{}
-	for _, col := range df.Columns() {
-		s := df.GetColumn(col)
-		row = append(row, s.Data()[0])
-	}
-
 	// get column format strings based on the type of the first row
-	colFormats := make([]string, len(row))
-	for i, cell := range row {
-		switch cell.(type) {
-		case float64:
-			colFormats[i] = "%.6f"
-		case int:
-			colFormats[i] = "%d"
-		default:
-			colFormats[i] = "%s"
-		}
-	}
+	colFormats := Map(df.Columns(), func(col string) string {
+		return seriesFormat(df.GetColumn(col))
+	})
 
 	// Add the data rows
-	for i := 0; i < df.Rows(); i++ {
+	rows := df.Rows()
+	if maxRows > 0 {
+		rows = min(rows, maxRows)
+	}
+	for i := 0; i < rows; i++ {
 		row := []string{}
 		for j, col := range df.Columns() {
 			s := df.GetColumn(col)
-			row = append(row, fmt.Sprintf(colFormats[j], s.Data()[i]))
+			row = append(row, formatCell(colFormats[j], s.Data()[i]))
 		}
 		data = append(data, row)
 	}
@@ -426,18 +533,116 @@ func (df *dataFrame) String() string {
 
 	// format the data
 	var buf strings.Builder
-	for _, row := range data {
+	for i, row := range data {
 		var args []any
 		for _, cell := range row {
 			args = append(args, cell)
 		}
 		buf.WriteString(fmt.Sprintf(format, args...))
+
+		if i == 0 && separator {
+			for _, l := range colLengths {
+				buf.WriteString(strings.Repeat("-", l) + " ")
+			}
+			buf.WriteString("\n")
+		}
+	}
+	if rows < df.Rows() {
+		fmt.Fprintf(&buf, "... %d more rows\n", df.Rows()-rows)
 	}
 	return strings.TrimRight(buf.String(), "\n")
 }
 
-// FromRecords creates a DataFrame from a slice of slices where each inner slice represents a row
-func FromRecords(data [][]any, columns []string) DataFrame {
+// firstValue returns the first non-null value, or nil if there is none.
+func firstValue(data []any) any {
+	for _, v := range data {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// seriesFormat returns the format string of the series based on the type of the first value which is not null.
+func seriesFormat(s Series) string {
+	return cellFormat(firstValue(s.Data()))
+}
+
+// formatCell formats a cell with the format of its column, a nil cell is a null value.
+func formatCell(format string, cell any) string {
+	switch cell := cell.(type) {
+	case nil:
+		return "null"
+	case time.Time:
+		return cell.Format(time.RFC3339)
+	}
+	return fmt.Sprintf(format, cell)
+}
+
+// timeLayout returns the layout to show the times of a series on a chart axis,
+// which is a date if they are all at midnight.
+func timeLayout(s Series) string {
+	for _, v := range s.Data() {
+		if t, ok := v.(time.Time); ok && t.Hour()+t.Minute()+t.Second()+t.Nanosecond() != 0 {
+			return "2006-01-02 15:04"
+		}
+	}
+	return time.DateOnly
+}
+
+// labels formats the values of a series as the labels of a chart axis.
+func labels(s Series) []string {
+	layout := timeLayout(s)
+	return Map(s.Data(), func(v any) string {
+		switch v := v.(type) {
+		case nil:
+			return ""
+		case time.Time:
+			return v.Format(layout)
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		default:
+			return fmt.Sprint(v)
+		}
+	})
+}
+
+func cellFormat(cell any) string {
+	switch cell.(type) {
+	case float64:
+		return "%.6f"
+	case int:
+		return "%d"
+	case bool:
+		return "%t"
+	default:
+		return "%s"
+	}
+}
+
+// RecordOption configures how FromRecords converts the cells of a column.
+type RecordOption func(*recordConfig)
+
+type recordConfig struct {
+	nullInvalid bool
+}
+
+// NullInvalid makes FromRecords store a cell which can't be converted to the type of its column as a null value,
+// instead of keeping it as is. A string cell in a numeric column is parsed as a number, and NaN is a null value.
+func NullInvalid() RecordOption {
+	return func(c *recordConfig) {
+		c.nullInvalid = true
+	}
+}
+
+// FromRecords creates a DataFrame from a slice of slices where each inner slice represents a row.
+// The type of a column is the type of its first non-null cell, and a column which mixes int and float64 cells is a float64 column.
+func FromRecords(data [][]any, columns []string, options ...RecordOption) DataFrame {
+	config := &recordConfig{}
+	for _, option := range options {
+		option(config)
+	}
+
 	// if row count is zero, return an empty DataFrame with the given columns
 	if len(data) == 0 {
 		return &dataFrame{columns: make(map[string]Series), order: columns}
@@ -464,12 +669,104 @@ func FromRecords(data [][]any, columns []string) DataFrame {
 		}
 
 		// Create a new Series and add it to the DataFrame
-		series := NewSeriesAny(columns[i], colData)
+		series := NewSeriesAny(columns[i], reconcile(colData, config.nullInvalid))
 		df.SetColumn(series)
 	}
 	return df
 }
 
+// FromMap creates a DataFrame from a map of column names to cells, with the columns in the given order,
+// since the order of a map is random. The columns must have the same length, and the cells of a column
+// must have one of the types of a Series, except that a column which mixes int and float64 cells is a float64 column.
+func FromMap(data map[string][]any, order []string) (DataFrame, error) {
+	if len(order) != len(data) {
+		return nil, fmt.Errorf("%d column names for %d columns", len(order), len(data))
+	}
+	df := &dataFrame{columns: make(map[string]Series), order: make([]string, 0, len(order))}
+	for _, name := range order {
+		cells, ok := data[name]
+		if !ok {
+			return nil, fmt.Errorf("column not found: %s", name)
+		}
+		if contains(df.order, name) {
+			return nil, fmt.Errorf("duplicate column: %s", name)
+		}
+		if rows := len(data[order[0]]); len(cells) != rows {
+			return nil, fmt.Errorf("column %s has %d rows, want %d", name, len(cells), rows)
+		}
+		cells = reconcile(cells, false)
+		if err := checkCells(name, cells); err != nil {
+			return nil, err
+		}
+		df.SetColumn(NewSeriesAny(name, cells))
+	}
+	return df, nil
+}
+
+// checkCells returns an error if the non-null cells of a column don't have the same supported type.
+func checkCells(name string, cells []any) error {
+	first := firstValue(cells)
+	switch first.(type) {
+	case nil, float64, int, string, bool, time.Time:
+	default:
+		return fmt.Errorf("unsupported type of column %s: %T", name, first)
+	}
+	for _, v := range cells {
+		if v != nil && reflect.TypeOf(v) != reflect.TypeOf(first) {
+			return fmt.Errorf("column %s mixes %T and %T", name, first, v)
+		}
+	}
+	return nil
+}
+
+// reconcile converts the cells of a column to a single type, which is the type of the first non-null cell,
+// or float64 if there are both int and float64 cells.
+// A cell which can't be converted is kept as is, or it is a null value if nullInvalid is true.
+func reconcile(data []any, nullInvalid bool) []any {
+	var kind any
+	hasFloat := false
+	for _, v := range data {
+		if kind == nil {
+			kind = v
+		}
+		if _, ok := v.(float64); ok {
+			hasFloat = true
+		}
+	}
+	if _, ok := kind.(int); ok && hasFloat {
+		kind = 0.0
+	}
+
+	ret := make([]any, len(data))
+	for i, v := range data {
+		ret[i] = v
+		switch kind.(type) {
+		case float64:
+			switch v := v.(type) {
+			case int:
+				ret[i] = float64(v)
+			case string:
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && nullInvalid {
+					ret[i] = f
+				}
+			}
+			if f, ok := ret[i].(float64); ok && math.IsNaN(f) && nullInvalid {
+				ret[i] = nil
+			}
+		case int:
+			if v, ok := v.(string); ok && nullInvalid {
+				if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+					ret[i] = n
+				}
+			}
+		}
+		if nullInvalid && ret[i] != nil && reflect.TypeOf(ret[i]) != reflect.TypeOf(kind) {
+			ret[i] = nil
+		}
+	}
+	return ret
+}
+
 // FromRandomValue generates a DataFrame with random float64 values.
 func FromRandomValue(rows, cols int, columns []string) DataFrame {
 	if len(columns) != cols {
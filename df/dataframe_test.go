@@ -0,0 +1,425 @@
+package df
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCopy(t *testing.T) {
+	d := NewDataFrame(NewSeries("a", []int{1, 2, 3}), NewSeries("b", []string{"x", "y", "z"}))
+
+	copied := d.Copy()
+	copied.GetColumn("a").Data()[0] = 100
+	copied.RemoveColumn("b")
+	if got := d.GetColumn("a").Data()[0]; got != 1 {
+		t.Errorf("changing a copy changed the original: got %v, want 1", got)
+	}
+	if got := len(d.Columns()); got != 2 {
+		t.Errorf("removing a column of a copy changed the original: got %d columns, want 2", got)
+	}
+
+	s := d.GetColumn("b")
+	s.Copy().Data()[0] = "changed"
+	if got := s.Data()[0]; got != "x" {
+		t.Errorf("changing a copy changed the series: got %v, want x", got)
+	}
+}
+
+func TestHeadTailCopy(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func(DataFrame) DataFrame
+		rows int
+	}{
+		{"Head", func(d DataFrame) DataFrame { return d.Head(2) }, 2},
+		{"Tail", func(d DataFrame) DataFrame { return d.Tail(2) }, 2},
+		// All rows are still a copy
+		{"Head all", func(d DataFrame) DataFrame { return d.Head(10) }, 3},
+		{"Tail all", func(d DataFrame) DataFrame { return d.Tail(10) }, 3},
+	} {
+		d := NewDataFrame(NewSeries("a", []int{1, 2, 3}))
+		got := tc.fn(d)
+		if got.Rows() != tc.rows {
+			t.Errorf("%s: got %d rows, want %d", tc.name, got.Rows(), tc.rows)
+		}
+		for i := range got.GetColumn("a").Data() {
+			got.GetColumn("a").Data()[i] = -1
+		}
+		if want := []any{1, 2, 3}; !slices.Equal(d.GetColumn("a").Data(), want) {
+			t.Errorf("%s: changing the result changed the original: got %v, want %v", tc.name, d.GetColumn("a").Data(), want)
+		}
+	}
+}
+
+func TestNoSharedData(t *testing.T) {
+	// Appending to the data of a head used to overwrite the rows of the original after it
+	d := NewDataFrame(NewSeries("a", []int{1, 2, 3}))
+	head := d.Head(1).GetColumn("a").Data()
+	_ = append(head, 100)
+	if got, want := d.GetColumn("a").Data(), []any{1, 2, 3}; !slices.Equal(got, want) {
+		t.Errorf("appending to a head changed the original: got %v, want %v", got, want)
+	}
+
+	data := []any{1, 2}
+	s := NewSeriesAny("a", data)
+	data[0] = 100
+	if got := s.Data()[0]; got != 1 {
+		t.Errorf("changing the slice changed the series: got %v, want 1", got)
+	}
+
+	rows := [][]any{{1, "x"}, {2, "y"}}
+	d = FromRecords(rows, []string{"a", "b"})
+	rows[0][0], rows[1][1] = 100, "changed"
+	if got, want := d.GetColumn("a").Data(), []any{1, 2}; !slices.Equal(got, want) {
+		t.Errorf("changing the records changed the DataFrame: got %v, want %v", got, want)
+	}
+	if got, want := d.GetColumn("b").Data(), []any{"x", "y"}; !slices.Equal(got, want) {
+		t.Errorf("changing the records changed the DataFrame: got %v, want %v", got, want)
+	}
+}
+
+func TestSyncDataFrame(t *testing.T) {
+	d := NewSyncDataFrame(NewDataFrame(NewSeries("a", []int{1, 2, 3})))
+
+	// The race detector reports the concurrent use of a DataFrame which is not synchronized
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				d.SetColumn(NewSeries(fmt.Sprintf("c%d", i), []int{j, j, j}))
+				d.Update(func(d DataFrame) {
+					d.RemoveColumn("tmp")
+					d.SetColumn(NewSeries("tmp", []int{j, j, j}))
+				})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = d.Rows()
+				_ = d.GetColumn("a")
+				_ = d.Head(2).String()
+				_ = d.HTML()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := len(d.Columns()), 6; got != want {
+		t.Errorf("got %d columns, want %d", got, want)
+	}
+}
+
+func TestAvgNull(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    Series
+		want float64
+	}{
+		{"leading null", NewSeries("a", []int{1, 2, 3}).Shift(1), 1.5},
+		{"float null", NewSeriesAny("a", []any{1.0, nil, 4.0}), 2.5},
+		{"NaN", NewSeries("a", []float64{1, math.NaN(), 3}), 2},
+		{"bool null", NewSeriesAny("a", []any{nil, true, false}), 0.5},
+		{"all null", NewSeriesAny("a", []any{nil, nil}), math.NaN()},
+	} {
+		got := tc.s.Avg().Data()[0].(float64)
+		if got != tc.want && !(math.IsNaN(got) && math.IsNaN(tc.want)) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewSeriesAny("t", []any{nil, start, start.Add(2 * time.Hour)})
+	if got, want := s.Avg().Data()[0], start.Add(time.Hour); got != want {
+		t.Errorf("time: got %v, want %v", got, want)
+	}
+}
+
+func TestAsNull(t *testing.T) {
+	if got := NewSeriesAny("a", []any{1.5, nil}).AsFloat64(); got[0] != 1.5 || !math.IsNaN(got[1]) {
+		t.Errorf("AsFloat64: got %v, want [1.5 NaN]", got)
+	}
+	if got, want := NewSeries("a", []int{1, 2}).Shift(1).AsInt(), []int{0, 1}; !slices.Equal(got, want) {
+		t.Errorf("AsInt: got %v, want %v", got, want)
+	}
+	if got, want := NewSeriesAny("a", []any{"x", nil}).AsString(), []string{"x", ""}; !slices.Equal(got, want) {
+		t.Errorf("AsString: got %v, want %v", got, want)
+	}
+	if got, want := NewSeriesAny("a", []any{nil, true}).AsBool(), []bool{false, true}; !slices.Equal(got, want) {
+		t.Errorf("AsBool: got %v, want %v", got, want)
+	}
+	now := time.Now()
+	if got, want := NewSeriesAny("a", []any{now, nil}).AsTime(), []time.Time{now, {}}; !slices.Equal(got, want) {
+		t.Errorf("AsTime: got %v, want %v", got, want)
+	}
+}
+
+// wrappedFrame is an implementation of DataFrame other than the one of the package.
+type wrappedFrame struct {
+	DataFrame
+}
+
+func TestPrintTableInterface(t *testing.T) {
+	d := NewDataFrame(NewSeries("a", []int{1, 2}), NewSeries("b", []string{"x", "y"}))
+	if got, want := tableText(wrappedFrame{d}, true, 1), "a b \n- - \n1 x \n... 1 more rows"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// It used to panic for another implementation
+	PrintTable(wrappedFrame{d}, MaxRows(1))
+}
+
+func TestFromMap(t *testing.T) {
+	data := map[string][]any{
+		"c": {1, 2.5, nil},
+		"a": {"x", nil, "z"},
+		"b": {true, false, true},
+	}
+	// The columns are in the given order, and ints are float64 in a column with float64 cells
+	for _, order := range [][]string{{"a", "b", "c"}, {"c", "a", "b"}} {
+		d, err := FromMap(data, order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.Columns(); !slices.Equal(got, order) {
+			t.Errorf("got columns %v, want %v", got, order)
+		}
+		if got, want := d.GetColumn("c").Data(), []any{1.0, 2.5, nil}; !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+
+	// The DataFrame doesn't share the cells of the map
+	d, _ := FromMap(data, []string{"a", "b", "c"})
+	data["a"][0] = "changed"
+	if got := d.GetColumn("a").Data()[0]; got != "x" {
+		t.Errorf("got %v, want x", got)
+	}
+
+	for _, tc := range []struct {
+		data  map[string][]any
+		order []string
+		want  string
+	}{
+		{map[string][]any{"a": {1, 2}, "b": {1}}, []string{"a", "b"}, "column b has 1 rows, want 2"},
+		{map[string][]any{"a": {1}, "b": {1, 2}}, []string{"a", "b"}, "column b has 2 rows, want 1"},
+		{map[string][]any{"a": {1}, "b": {1}}, []string{"a"}, "1 column names for 2 columns"},
+		{map[string][]any{"a": {1}}, []string{"b"}, "column not found: b"},
+		{map[string][]any{"a": {1}, "b": {1}}, []string{"a", "a"}, "duplicate column: a"},
+		{map[string][]any{"a": {1, "x"}}, []string{"a"}, "column a mixes int and string"},
+		{map[string][]any{"a": {int64(1)}}, []string{"a"}, "unsupported type of column a: int64"},
+	} {
+		if _, err := FromMap(tc.data, tc.order); err == nil || err.Error() != tc.want {
+			t.Errorf("got error %v, want %q", err, tc.want)
+		}
+	}
+}
+
+func TestResampleOutlier(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := []time.Time{start, start.Add(3 * time.Second)}
+	d := NewDataFrame(NewTimeSeries("t", times), NewSeries("v", []int{1, 2}))
+	if got := d.Resample("t", "1s", nil).Rows(); got != 4 {
+		t.Errorf("got %d rows, want 4 with the empty buckets", got)
+	}
+
+	// A time far away would need billions of buckets of a second
+	times = append(times, start.AddDate(100, 0, 0))
+	d = NewDataFrame(NewTimeSeries("t", times), NewSeries("v", []int{1, 2, 3}))
+	r := d.Resample("t", "1s", nil)
+	if got := r.Rows(); got != 3 {
+		t.Fatalf("got %d rows, want 3 without the empty buckets", got)
+	}
+	if got, want := r.GetColumn("v").Data(), []any{1.0, 2.0, 3.0}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestResampleDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	// Every hour of the days when the clocks go forward and back
+	hours := func(year int, month time.Month, day int) DataFrame {
+		var times []time.Time
+		for h := 0; h < 71; h++ {
+			times = append(times, time.Date(year, month, day, 0, 0, 0, 0, ny).Add(time.Duration(h)*time.Hour))
+		}
+		return NewDataFrame(NewTimeSeries("t", times), NewSeries("v", make([]int, len(times))))
+	}
+	labels := func(r DataFrame) []string {
+		var labels []string
+		for _, v := range r.GetColumn("t").Data() {
+			labels = append(labels, v.(time.Time).Format("Jan 2 15:04 MST"))
+		}
+		return labels
+	}
+	tests := []struct {
+		df         DataFrame
+		freq       string
+		from       int
+		wantLabels []string
+		wantCounts []any
+	}{
+		{hours(2024, 3, 9), "1d", 0, []string{"Mar 9 00:00 EST", "Mar 10 00:00 EST", "Mar 11 00:00 EDT"}, []any{24, 23, 24}},
+		{hours(2024, 11, 2), "1d", 0, []string{"Nov 2 00:00 EDT", "Nov 3 00:00 EDT", "Nov 4 00:00 EST"}, []any{24, 25, 22}},
+		// The hours of a day are on the wall clock, and the repeated hour is a bucket of its own
+		{hours(2024, 3, 9), "2h", 12, []string{"Mar 10 00:00 EST", "Mar 10 03:00 EDT", "Mar 10 04:00 EDT"}, []any{2, 1, 2}},
+		{hours(2024, 11, 2), "2h", 12, []string{"Nov 3 00:00 EDT", "Nov 3 02:00 EST", "Nov 3 04:00 EST"}, []any{3, 2, 2}},
+		{hours(2024, 11, 2), "1h", 24, []string{"Nov 3 00:00 EDT", "Nov 3 01:00 EDT", "Nov 3 01:00 EST"}, []any{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		r := tt.df.Resample("t", tt.freq, map[string]string{"v": "count"})
+		n := len(tt.wantLabels)
+		if got := labels(r)[tt.from : tt.from+n]; !slices.Equal(got, tt.wantLabels) {
+			t.Errorf("%s: got %v, want %v", tt.freq, got, tt.wantLabels)
+		}
+		if got := r.GetColumn("v").Data()[tt.from : tt.from+n]; !slices.Equal(got, tt.wantCounts) {
+			t.Errorf("%s: got %v, want %v", tt.freq, got, tt.wantCounts)
+		}
+	}
+}
+
+func TestResampleZones(t *testing.T) {
+	// The same day in other time zones is bucketed in the time zone of the first time
+	utc := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	east := time.FixedZone("UTC+8", 8*60*60)
+	times := []time.Time{utc, utc.Add(2 * time.Hour).In(east), utc.Add(5 * time.Hour).In(east)}
+	d := NewDataFrame(NewTimeSeries("t", times), NewSeries("v", []int{1, 2, 3}))
+	r := d.Resample("t", "1d", map[string]string{"v": "sum"})
+	if got, want := r.GetColumn("v").Data(), []any{3.0, 3.0}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := r.GetColumn("t").Data()[1].(time.Time); !got.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %v, want the UTC midnight", got)
+	}
+
+	// Weeks start on Monday, and a zero time is a valid time
+	times = []time.Time{time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), {}}
+	d = NewDataFrame(NewTimeSeries("t", times), NewSeries("v", []int{1, 2}))
+	r = d.Resample("t", "1w", map[string]string{"v": "sum"})
+	if got := r.GetColumn("t").Data()[0].(time.Time); !got.IsZero() {
+		t.Errorf("got %v, want the zero time", got)
+	}
+	if got := r.GetColumn("t").Data()[r.Rows()-1].(time.Time); got.Weekday() != time.Monday || got.Day() != 8 {
+		t.Errorf("got %v, want Monday, January 8", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	left := NewDataFrame(NewSeries("k", []int{1, 2, 3}), NewSeries("a", []string{"x", "y", "z"}))
+	right := NewDataFrame(NewSeries("k", []float64{3, 1, 4}), NewSeries("b", []string{"p", "q", "r"}))
+
+	// The int and the float64 keys of the same value match
+	inner := left.Merge(right, MergeOptions{On: "k"})
+	if got, want := inner.GetColumn("b").Data(), []any{"q", "p"}; !slices.Equal(got, want) {
+		t.Errorf("inner: got %v, want %v", got, want)
+	}
+
+	// A right join is in the order of the right DataFrame, and the keys are float64 like the right ones
+	r := left.Merge(right, MergeOptions{On: "k", How: "right"})
+	if got, want := r.GetColumn("k").Data(), []any{3.0, 1.0, 4.0}; !slices.Equal(got, want) {
+		t.Errorf("right keys: got %v, want %v", got, want)
+	}
+	if got, want := r.GetColumn("a").Data(), []any{"z", "x", nil}; !slices.Equal(got, want) {
+		t.Errorf("right: got %v, want %v", got, want)
+	}
+}
+
+func TestMergeHow(t *testing.T) {
+	// Duplicate keys, keys of mixed types, and null and NaN keys which don't match
+	left := NewDataFrame(
+		NewSeriesAny("k", []any{1, 2, 2, nil, math.NaN(), "a"}),
+		NewSeries("a", []string{"l0", "l1", "l2", "l3", "l4", "l5"}),
+	)
+	right := NewDataFrame(
+		NewSeriesAny("k", []any{2, 1.0, 2, nil, math.NaN(), "a", 5}),
+		NewSeries("b", []string{"r0", "r1", "r2", "r3", "r4", "r5", "r6"}),
+	)
+	tests := []struct {
+		how     string
+		a, b    string
+		indices string
+	}{
+		{"inner", "[l0 l1 l1 l2 l2 l5]", "[r1 r0 r2 r0 r2 r5]", "[both both both both both both]"},
+		{"left", "[l0 l1 l1 l2 l2 l3 l4 l5]", "[r1 r0 r2 r0 r2 <nil> <nil> r5]",
+			"[both both both both both left_only left_only both]"},
+		{"right", "[l1 l2 l0 l1 l2 <nil> <nil> l5 <nil>]", "[r0 r0 r1 r2 r2 r3 r4 r5 r6]",
+			"[both both both both both right_only right_only both right_only]"},
+		{"outer", "[l0 l1 l1 l2 l2 l3 l4 l5 <nil> <nil> <nil>]", "[r1 r0 r2 r0 r2 <nil> <nil> r5 r3 r4 r6]",
+			"[both both both both both left_only left_only both right_only right_only right_only]"},
+	}
+	for _, tt := range tests {
+		r := left.Merge(right, MergeOptions{On: "k", How: tt.how, Indicator: true})
+		if got := fmt.Sprint(r.GetColumn("a").Data()); got != tt.a {
+			t.Errorf("%s: got a %v, want %v", tt.how, got, tt.a)
+		}
+		if got := fmt.Sprint(r.GetColumn("b").Data()); got != tt.b {
+			t.Errorf("%s: got b %v, want %v", tt.how, got, tt.b)
+		}
+		if got := fmt.Sprint(r.GetColumn("_merge").Data()); got != tt.indices {
+			t.Errorf("%s: got _merge %v, want %v", tt.how, got, tt.indices)
+		}
+	}
+}
+
+func TestMergeDuplicateColumn(t *testing.T) {
+	for _, tc := range []struct {
+		left, right DataFrame
+		opts        MergeOptions
+	}{
+		// The suffixed name of a shared column is another column
+		{
+			NewDataFrame(NewSeries("k", []int{1}), NewSeries("v", []int{1}), NewSeries("v_x", []int{1})),
+			NewDataFrame(NewSeries("k", []int{1}), NewSeries("v", []int{1})),
+			MergeOptions{On: "k"},
+		},
+		// The indicator is a column of a side
+		{
+			NewDataFrame(NewSeries("k", []int{1}), NewSeries("_merge", []int{1})),
+			NewDataFrame(NewSeries("k", []int{1})),
+			MergeOptions{On: "k", Indicator: true},
+		},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "duplicate column") {
+					t.Errorf("got panic %v, want a duplicate column", r)
+				}
+			}()
+			tc.left.Merge(tc.right, tc.opts)
+		}()
+	}
+}
+
+func TestCumsumNull(t *testing.T) {
+	s := NewSeriesAny("a", []any{1.0, math.NaN(), nil, 2.0})
+	if got, want := s.Cumsum().Data(), []any{1.0, nil, nil, 3.0}; !slices.Equal(got, want) {
+		t.Errorf("Cumsum: got %v, want %v", got, want)
+	}
+	if got, want := s.Cumprod().Data(), []any{1.0, nil, nil, 2.0}; !slices.Equal(got, want) {
+		t.Errorf("Cumprod: got %v, want %v", got, want)
+	}
+	if got, want := NewSeries("a", []int{1, 2, 3}).Shift(1).Cumsum().Data(), []any{nil, 1, 3}; !slices.Equal(got, want) {
+		t.Errorf("Cumsum of ints: got %v, want %v", got, want)
+	}
+
+	// A NaN in the middle is skipped, and the running values go on after it
+	s = NewSeries("a", []float64{1, 2, math.NaN(), 3, 4})
+	if got, want := s.Cumsum().Data(), []any{1.0, 3.0, nil, 6.0, 10.0}; !slices.Equal(got, want) {
+		t.Errorf("Cumsum: got %v, want %v", got, want)
+	}
+	if got, want := s.Cumprod().Data(), []any{1.0, 2.0, nil, 6.0, 24.0}; !slices.Equal(got, want) {
+		t.Errorf("Cumprod: got %v, want %v", got, want)
+	}
+	if got, want := NewSeriesAny("a", []any{2, nil, 3, 4}).Cumprod().Data(), []any{2, nil, 6, 24}; !slices.Equal(got, want) {
+		t.Errorf("Cumprod of ints: got %v, want %v", got, want)
+	}
+}
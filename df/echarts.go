@@ -1,7 +1,9 @@
 package df
 
 import (
+	"cmp"
 	"fmt"
+	"strings"
 
 	"github.com/discoverkl/goterm/term"
 	"github.com/go-echarts/go-echarts/v2/render"
@@ -21,24 +23,38 @@ const (
 	DivMode            RenderMode = "div"
 )
 
+// EChartRenderMode sets the default render mode of echarts. A chart can override it with SetRenderMode.
 func EChartRenderMode(mode RenderMode) {
 	echartRenderMode = mode
 }
 
 type EChart struct {
 	chart render.Renderer
+
+	// Render mode of this chart, the default render mode is used if it's empty
+	mode RenderMode
 }
 
 func NewEChart(chart render.Renderer) *EChart {
 	return &EChart{chart: chart}
 }
 
+// SetRenderMode sets the render mode of this chart only, so that iframe and div charts can be mixed.
+func (c *EChart) SetRenderMode(mode RenderMode) *EChart {
+	c.mode = mode
+	return c
+}
+
+func (c *EChart) renderMode() RenderMode {
+	return cmp.Or(c.mode, echartRenderMode)
+}
+
 func (c *EChart) HTML() string {
-	html := string(c.chart.RenderContent())
+	html := injectResizeScript(string(c.chart.RenderContent()))
 
-	switch echartRenderMode {
+	switch c.renderMode() {
 	case IFrameMode:
-		// Chart class has a minimum width of 916px to fit the echart.
+		// Chart class fits the width of the container, with a minimum width of 320px.
 		return term.EscapeIframe(html, "echart")
 	case DivMode:
 		// Wrap the whole page in a div will prevent auto iframe wraping in the PrintBlockSize function.
@@ -50,12 +66,12 @@ func (c *EChart) HTML() string {
 
 func (c *EChart) Options() []term.BlockOption {
 	return []term.BlockOption{
-		term.SizeOption(0, eChartDefaultHeight()),
+		term.SizeOption(0, eChartDefaultHeight(c.renderMode())),
 	}
 }
 
-func eChartDefaultHeight() int {
-	switch echartRenderMode {
+func eChartDefaultHeight(mode RenderMode) int {
+	switch mode {
 	case IFrameMode:
 		return eChartIframeHeight
 	case DivMode:
@@ -68,3 +84,32 @@ func eChartDefaultHeight() int {
 func escapeEChartWithDiv(html string) string {
 	return fmt.Sprintf("<div class='echart'>%s</div>", html)
 }
+
+// echartResizeScript resizes the charts next to the script when their containers are resized,
+// since an echart is drawn on a canvas of a fixed size.
+const echartResizeScript = `<script>
+(function() {
+    const root = document.currentScript && document.currentScript.parentElement;
+    if (!root || typeof ResizeObserver === 'undefined' || typeof echarts === 'undefined') {
+        return;
+    }
+    root.querySelectorAll('div.item').forEach(function(el) {
+        new ResizeObserver(function() {
+            const chart = echarts.getInstanceByDom(el);
+            if (chart) {
+                chart.resize();
+            }
+        }).observe(el);
+    });
+})();
+</script>
+`
+
+// injectResizeScript adds the resize script to the end of the body of a chart page.
+func injectResizeScript(html string) string {
+	i := strings.LastIndex(html, "</body>")
+	if i < 0 {
+		return html + echartResizeScript
+	}
+	return html[:i] + echartResizeScript + html[i:]
+}
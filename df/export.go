@@ -0,0 +1,43 @@
+package df
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/discoverkl/goterm/term"
+)
+
+// SaveChart saves a chart, or any other block element, to a standalone HTML file.
+// The echarts script of a chart is loaded from the assets host of the chart, which is a CDN by default.
+// To view the file offline, set the AssetsHost of the chart's initialization options to a local copy of the assets.
+func SaveChart(path string, c term.BlockElement) error {
+	return os.WriteFile(path, []byte(term.BlockPage(c)), 0644)
+}
+
+// dataHTML returns the HTML which embeds the data of a chart, see the EmbedData option.
+// The data is in a JSON script element with the column names and the rows, and in a CSV download link.
+func dataHTML(d DataFrame) string {
+	rows := make([][]any, d.Rows())
+	for i := range rows {
+		rows[i] = make([]any, len(d.Columns()))
+		for j, col := range d.Columns() {
+			v := d.GetColumn(col).Data()[i]
+			if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+				v = nil
+			}
+			rows[i][j] = v
+		}
+	}
+	data, _ := json.Marshal(map[string]any{"columns": d.Columns(), "data": rows})
+
+	var csv bytes.Buffer
+	writeCSV(&csv, d)
+
+	return fmt.Sprintf(`<div class="goterm-chart-data"><script type="application/json">%s</script>`+
+		`<a download="data.csv" href="data:text/csv;base64,%s">Download data</a></div>`,
+		data, base64.StdEncoding.EncodeToString(csv.Bytes()))
+}
@@ -0,0 +1,120 @@
+package df
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/discoverkl/goterm/term"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// liveCharts counts the live charts, to give each chart a unique id on the page.
+var liveCharts atomic.Int64
+
+// liveScript draws the points of the live charts, which are sent as "goterm:live" events. The points are kept
+// by the chart id, so that the points which arrive before the chart is ready are drawn once it's ready.
+const liveScript = `<script>
+(function() {
+    function draw(id) {
+        const state = window.gotermLive[id];
+        const el = document.getElementById(id);
+        const chart = el && typeof echarts !== 'undefined' && echarts.getInstanceByDom(el);
+        if (chart) {
+            chart.setOption({
+                legend: {data: state.names},
+                series: state.names.map(function(name) {
+                    return {name: name, type: 'line', showSymbol: false, data: state.data[name]};
+                })
+            });
+        }
+    }
+
+    if (!window.gotermLive) {
+        window.gotermLive = {};
+        window.addEventListener('goterm:live', function(e) {
+            const p = e.detail;
+            const state = window.gotermLive[p.id] = window.gotermLive[p.id] || {names: [], data: {}};
+            for (const name in p.values) {
+                if (!(name in state.data)) {
+                    state.names.push(name);
+                    state.data[name] = [];
+                }
+                state.data[name].push([p.x, p.values[name]]);
+            }
+            draw(p.id);
+        });
+    }
+    Object.keys(window.gotermLive).forEach(draw);
+})();
+</script>`
+
+// LiveChart is a line chart which grows as points are pushed to it, for a live dashboard on the page
+// served by the BindPort and EnableSSE options. The points are sent as events of term.SendEvent,
+// so they are not part of the output: a page only shows the points pushed while it's open.
+type LiveChart struct {
+	id string
+}
+
+// NewLiveChart prints an empty line chart with a numeric x axis, see LiveChart.
+// It supports the Name, XName, YName, Size and Minimal options.
+func NewLiveChart(options ...ChartOption) *LiveChart {
+	c := &chartConfig{}
+	for _, option := range options {
+		option(c)
+	}
+
+	id := fmt.Sprintf("goterm_live_%d", liveCharts.Add(1))
+	line := charts.NewLine()
+	legend := opts.Legend{}
+	title := opts.Title{Title: c.name}
+	if c.minimal {
+		legend.Show = opts.Bool(false)
+		title = opts.Title{}
+	}
+	line.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:   "100%",
+			Theme:   echartTheme(),
+			ChartID: id,
+		}),
+		charts.WithTitleOpts(title),
+		charts.WithLegendOpts(legend),
+		charts.WithTooltipOpts(opts.Tooltip{Trigger: "axis"}),
+		charts.WithXAxisOpts(opts.XAxis{Name: c.xLabel, Type: "value"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: c.yLabel}),
+	)
+
+	// The chart must be in the page to be updated by the pushes, so it can't be in an iframe
+	ops := []term.BlockOption{}
+	if c.width != 0 || c.height != 0 {
+		ops = append(ops, term.SizeOption(c.width, c.height))
+	}
+	term.Block(NewEChart(line).SetRenderMode(DivMode), ops...)
+	term.PrintHtml(liveScript)
+	return &LiveChart{id: id}
+}
+
+// Push adds a point to the series of the chart, the values are by series name. A new name adds a series.
+// A NaN value is a gap in its series, and a NaN x is ignored.
+func (l *LiveChart) Push(x float64, series map[string]float64) {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return
+	}
+	values := map[string]any{}
+	for name, v := range series {
+		values[name] = v
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			values[name] = nil
+		}
+	}
+	term.SendEvent("live", livePoint{ID: l.id, X: x, Values: values})
+}
+
+// livePoint is the data of a "goterm:live" event.
+type livePoint struct {
+	ID     string         `json:"id"`
+	X      float64        `json:"x"`
+	Values map[string]any `json:"values"`
+}
@@ -0,0 +1,66 @@
+package df
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/discoverkl/goterm/term"
+)
+
+func TestLiveChartPush(t *testing.T) {
+	output := func(pushes int) string {
+		term.Open(term.Format(term.Custom))
+		c := NewLiveChart()
+		for i := range pushes {
+			c.Push(float64(i), map[string]float64{"a": float64(i)})
+		}
+		term.Close()
+		return strings.Join(slices.Collect(term.HTML(false)), "")
+	}
+
+	// The pushes are sent as events, which are not kept in the output
+	if a, b := output(0), output(100); len(a) != len(b) {
+		t.Errorf("the pushes grew the output from %d to %d bytes", len(a), len(b))
+	}
+}
+
+func TestLiveChartEvent(t *testing.T) {
+	term.Open(term.Format(term.Custom), term.EnableSSE())
+	server := httptest.NewServer(term.Handler())
+	defer server.Close()
+	// Closing the terminal ends the stream of events
+	defer term.Close()
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	readUntil := func(prefix string) string {
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		}
+	}
+
+	// The page receives the chart, and then the pushes
+	c := NewLiveChart()
+	readUntil("event: html")
+	c.Push(1, map[string]float64{"a": 2, "b": math.NaN()})
+	readUntil("event: custom")
+	want := fmt.Sprintf(`data: {"name":"live","data":{"id":%q,"x":1,"values":{"a":2,"b":null}}}`+"\n", c.id)
+	if got := readUntil("data: "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
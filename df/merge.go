@@ -0,0 +1,172 @@
+package df
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// MergeOptions configures DataFrame.Merge.
+type MergeOptions struct {
+	// On is the name of the key column, which must exist in both DataFrames.
+	On string
+
+	// How is one of "inner", "left", "right" and "outer". The default is "inner".
+	How string
+
+	// Suffixes are appended to the names of the other columns which exist in both DataFrames.
+	// The default is "_x" for the left one and "_y" for the right one.
+	Suffixes [2]string
+
+	// Indicator adds a "_merge" column which tells where each row comes from:
+	// "both", "left_only" or "right_only".
+	Indicator bool
+}
+
+// Merge joins the rows of two DataFrames which have the same key, like a database join.
+// The result has the key column, followed by the other columns of the left and the right DataFrames.
+// The cells of a row which has no match on one side are null.
+// Rows are in the order of the left DataFrame, followed by the unmatched rows of the right one,
+// except for a right join, whose rows are in the order of the right DataFrame.
+// Numeric keys match by value, so the int key 1 matches the float64 key 1.0, and the key column of the result
+// is float64 if the key columns are int and float64. Null and NaN keys don't match any key, even another
+// null or NaN, like the null values of SQL, so their rows are only in the result as unmatched rows.
+// It panics if the key column does not exist, How is invalid, or a suffixed name or the "_merge" column
+// is the name of another column of the result.
+func (df *dataFrame) Merge(other DataFrame, opts MergeOptions) DataFrame {
+	how := cmp.Or(opts.How, "inner")
+	if !slices.Contains([]string{"inner", "left", "right", "outer"}, how) {
+		panic(fmt.Sprintf("unsupported merge: %s", how))
+	}
+	suffixes := opts.Suffixes
+	if suffixes == [2]string{} {
+		suffixes = [2]string{"_x", "_y"}
+	}
+	leftKey, rightKey := df.GetColumn(opts.On), other.GetColumn(opts.On)
+	if leftKey == nil || rightKey == nil {
+		panic(fmt.Sprintf("column not found in both DataFrames: %s", opts.On))
+	}
+
+	// Pair the rows, -1 means no row on that side
+	type pair struct {
+		left, right int
+		indicator   string
+	}
+	var pairs []pair
+	if how == "right" {
+		leftRows := indexKeys(leftKey)
+		for j, key := range rightKey.Data() {
+			if k, ok := mergeKey(key); ok && len(leftRows[k]) > 0 {
+				for _, i := range leftRows[k] {
+					pairs = append(pairs, pair{i, j, "both"})
+				}
+			} else {
+				pairs = append(pairs, pair{-1, j, "right_only"})
+			}
+		}
+	} else {
+		rightRows := indexKeys(rightKey)
+		matched := make([]bool, rightKey.Len())
+		for i, key := range leftKey.Data() {
+			if k, ok := mergeKey(key); ok && len(rightRows[k]) > 0 {
+				for _, j := range rightRows[k] {
+					pairs = append(pairs, pair{i, j, "both"})
+					matched[j] = true
+				}
+			} else if how == "left" || how == "outer" {
+				pairs = append(pairs, pair{i, -1, "left_only"})
+			}
+		}
+		if how == "outer" {
+			for j := range matched {
+				if !matched[j] {
+					pairs = append(pairs, pair{-1, j, "right_only"})
+				}
+			}
+		}
+	}
+
+	// The key column takes the key from either side, and it's a float64 column if one side is int
+	// and the other is float64
+	_, leftInt := firstValue(leftKey.Data()).(int)
+	_, leftFloat := firstValue(leftKey.Data()).(float64)
+	_, rightInt := firstValue(rightKey.Data()).(int)
+	_, rightFloat := firstValue(rightKey.Data()).(float64)
+	toFloat := leftInt && rightFloat || leftFloat && rightInt
+	keys := make([]any, len(pairs))
+	for k, p := range pairs {
+		if p.left >= 0 {
+			keys[k] = leftKey.Data()[p.left]
+		} else {
+			keys[k] = rightKey.Data()[p.right]
+		}
+		if v, ok := keys[k].(int); ok && toFloat {
+			keys[k] = float64(v)
+		}
+	}
+	columns := []Series{&series{name: opts.On, data: keys}}
+
+	// Add the other columns of both sides
+	var add = func(d DataFrame, side int, suffix string, shared DataFrame) {
+		for _, name := range d.Columns() {
+			if name == opts.On {
+				continue
+			}
+			data := d.GetColumn(name).Data()
+			values := make([]any, len(pairs))
+			for k, p := range pairs {
+				if i := [2]int{p.left, p.right}[side]; i >= 0 {
+					values[k] = data[i]
+				}
+			}
+			if shared.GetColumn(name) != nil {
+				name += suffix
+			}
+			columns = append(columns, &series{name: name, data: values})
+		}
+	}
+	add(df, 0, suffixes[0], other)
+	add(other, 1, suffixes[1], df)
+
+	if opts.Indicator {
+		indicators := make([]any, len(pairs))
+		for k, p := range pairs {
+			indicators[k] = p.indicator
+		}
+		columns = append(columns, &series{name: "_merge", data: indicators})
+	}
+	var names []string
+	for _, s := range columns {
+		if contains(names, s.Name()) {
+			panic(fmt.Sprintf("duplicate column: %s", s.Name()))
+		}
+		names = append(names, s.Name())
+	}
+	return NewDataFrame(columns...)
+}
+
+// indexKeys returns the rows of each key of the key column, without the null and NaN keys.
+func indexKeys(keys Series) map[any][]int {
+	rows := map[any][]int{}
+	for i, key := range keys.Data() {
+		if k, ok := mergeKey(key); ok {
+			rows[k] = append(rows[k], i)
+		}
+	}
+	return rows
+}
+
+// mergeKey returns the key of a map for a cell of a key column. A float64 which is a whole number in the range
+// of int is converted to int, so that it matches the int of the same value. It returns false for a null or NaN
+// cell, which doesn't match any key.
+func mergeKey(v any) (any, bool) {
+	f, ok := v.(float64)
+	switch {
+	case v == nil, ok && math.IsNaN(f):
+		return nil, false
+	case ok && f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64:
+		return int(f), true
+	}
+	return v, true
+}
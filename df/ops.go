@@ -0,0 +1,161 @@
+package df
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FilterMask returns a new DataFrame with the rows where the boolean mask is true.
+// It panics if the length of the mask does not match the number of rows.
+func (df *dataFrame) FilterMask(mask Series) DataFrame {
+	if mask.Len() != df.Rows() {
+		panic(fmt.Sprintf("mask length %d does not match %d rows", mask.Len(), df.Rows()))
+	}
+
+	columns := []Series{}
+	for _, name := range df.order {
+		var data []any
+		for i, v := range df.GetColumn(name).Data() {
+			if keep, _ := mask.Data()[i].(bool); keep {
+				data = append(data, v)
+			}
+		}
+		columns = append(columns, &series{name: name, data: data})
+	}
+	return NewDataFrame(columns...)
+}
+
+// Shift returns a new DataFrame with the values of the columns shifted by n rows, see Series.Shift.
+// If no column is given, all columns are shifted, otherwise the other columns are kept as they are.
+// It panics if a column does not exist.
+func (df *dataFrame) Shift(n int, columns ...string) DataFrame {
+	for _, name := range columns {
+		if !contains(df.order, name) {
+			panic(fmt.Sprintf("column not found: %s", name))
+		}
+	}
+
+	shifted := []Series{}
+	for _, name := range df.order {
+		s := df.GetColumn(name)
+		if len(columns) == 0 || contains(columns, name) {
+			s = s.Shift(n)
+		}
+		shifted = append(shifted, s)
+	}
+	return NewDataFrame(shifted...)
+}
+
+// Shift returns the series with the values moved forward by n positions, so the value at i is the
+// one at i-n. The positions which are moved in are null. A positive n is a lag and a negative n is a lead,
+// and the series is all null if |n| is not less than its length.
+func (s *series) Shift(n int) Series {
+	data := make([]any, len(s.data))
+	for i := range data {
+		if j := i - n; j >= 0 && j < len(s.data) {
+			data[i] = s.data[j]
+		}
+	}
+	return &series{name: s.name, data: data}
+}
+
+func (s *series) Gt(v float64) Series {
+	return s.compare(func(x float64) bool { return x > v })
+}
+
+func (s *series) Lt(v float64) Series {
+	return s.compare(func(x float64) bool { return x < v })
+}
+
+func (s *series) Eq(v any) Series {
+	// Numbers are compared by value, so that 1 equals 1.0
+	if y, ok := toNumber(v); ok {
+		return s.compare(func(x float64) bool { return x == y })
+	}
+	return &series{name: s.name, data: Map(s.data, func(x any) any {
+		return x == v
+	})}
+}
+
+// compare returns a boolean series of the numeric values, a null or non-numeric value is false.
+func (s *series) compare(f func(x float64) bool) Series {
+	return &series{name: s.name, data: Map(s.data, func(v any) any {
+		x, ok := toNumber(v)
+		return ok && f(x)
+	})}
+}
+
+func (s *series) Add(other Series) Series {
+	return s.zip(other, func(x, y float64) float64 { return x + y })
+}
+
+func (s *series) Sub(other Series) Series {
+	return s.zip(other, func(x, y float64) float64 { return x - y })
+}
+
+func (s *series) Mul(other Series) Series {
+	return s.zip(other, func(x, y float64) float64 { return x * y })
+}
+
+func (s *series) Div(other Series) Series {
+	return s.zip(other, func(x, y float64) float64 { return x / y })
+}
+
+func (s *series) AddScalar(v float64) Series {
+	return s.apply(func(x float64) float64 { return x + v })
+}
+
+func (s *series) SubScalar(v float64) Series {
+	return s.apply(func(x float64) float64 { return x - v })
+}
+
+func (s *series) MulScalar(v float64) Series {
+	return s.apply(func(x float64) float64 { return x * v })
+}
+
+func (s *series) DivScalar(v float64) Series {
+	return s.apply(func(x float64) float64 { return x / v })
+}
+
+// apply maps each numeric value with f, a null or non-numeric value results in NaN.
+func (s *series) apply(f func(x float64) float64) Series {
+	return &series{name: s.name, data: Map(s.data, func(v any) any {
+		x, ok := toNumber(v)
+		if !ok {
+			return math.NaN()
+		}
+		return f(x)
+	})}
+}
+
+// zip combines the values of two series at the same index with f, a null or non-numeric value results in NaN.
+func (s *series) zip(other Series, f func(x, y float64) float64) Series {
+	if s.Len() != other.Len() {
+		panic(fmt.Sprintf("series length %d does not match %d", s.Len(), other.Len()))
+	}
+	data := make([]any, s.Len())
+	for i := range data {
+		x, okx := toNumber(s.data[i])
+		y, oky := toNumber(other.Data()[i])
+		if okx && oky {
+			data[i] = f(x, y)
+		} else {
+			data[i] = math.NaN()
+		}
+	}
+	return &series{name: s.name, data: data}
+}
+
+// toNumber converts a numeric value to float64. Times are unix seconds.
+func toNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, !math.IsNaN(v)
+	case int:
+		return float64(v), true
+	case time.Time:
+		return float64(v.UnixNano()) / 1e9, true
+	}
+	return 0, false
+}
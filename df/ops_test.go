@@ -0,0 +1,96 @@
+package df
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestFilterMask(t *testing.T) {
+	d := NewDataFrame(NewSeries("a", []int{1, 2, 3, 4, 5}), NewSeries("b", []string{"v", "w", "x", "y", "z"}))
+
+	// Only the true cells keep their rows, and null, NaN and non-bool cells drop them
+	mask := NewSeriesAny("m", []any{true, nil, math.NaN(), "true", true})
+	r := d.FilterMask(mask)
+	if got, want := r.GetColumn("b").Data(), []any{"v", "z"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := r.Columns(), d.Columns(); !slices.Equal(got, want) {
+		t.Errorf("got columns %v, want %v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("a mask of another length did not panic")
+		}
+	}()
+	d.FilterMask(NewSeries("m", []bool{true, false}))
+}
+
+func TestCompare(t *testing.T) {
+	// A mixed column with null, NaN and non-numeric cells, which are false for the numeric comparisons
+	s := NewSeriesAny("a", []any{1, 2.5, nil, math.NaN(), "2", true, 3})
+	for _, tc := range []struct {
+		name string
+		got  Series
+		want []any
+	}{
+		{"Gt", s.Gt(1), []any{false, true, false, false, false, false, true}},
+		{"Lt", s.Lt(3), []any{true, true, false, false, false, false, false}},
+		{"Eq number", s.Eq(1.0), []any{true, false, false, false, false, false, false}},
+		{"Eq int", s.Eq(3), []any{false, false, false, false, false, false, true}},
+		{"Eq string", s.Eq("2"), []any{false, false, false, false, true, false, false}},
+		{"Eq bool", s.Eq(true), []any{false, false, false, false, false, true, false}},
+		{"Eq null", s.Eq(nil), []any{false, false, true, false, false, false, false}},
+		// NaN equals nothing, not even NaN
+		{"Eq NaN", s.Eq(math.NaN()), []any{false, false, false, false, false, false, false}},
+	} {
+		if got := tc.got.Data(); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// The comparisons are masks of the DataFrame
+	d := NewDataFrame(s, NewSeries("b", []int{0, 1, 2, 3, 4, 5, 6}))
+	if got, want := d.FilterMask(s.Gt(1)).GetColumn("b").Data(), []any{1, 6}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	// ints and float64s are mixed as float64, and a null or non-numeric cell is NaN
+	a := NewSeriesAny("a", []any{1, 2.5, nil, math.NaN(), "x", 6})
+	b := NewSeriesAny("b", []any{2, 2, 1, 1, 1, 0})
+	inf := math.Inf(1)
+	for _, tc := range []struct {
+		name string
+		got  Series
+		want []float64
+	}{
+		{"Add", a.Add(b), []float64{3, 4.5, math.NaN(), math.NaN(), math.NaN(), 6}},
+		{"Sub", a.Sub(b), []float64{-1, 0.5, math.NaN(), math.NaN(), math.NaN(), 6}},
+		{"Mul", a.Mul(b), []float64{2, 5, math.NaN(), math.NaN(), math.NaN(), 0}},
+		// Division by zero follows float64 arithmetic
+		{"Div", a.Div(b), []float64{0.5, 1.25, math.NaN(), math.NaN(), math.NaN(), inf}},
+		{"AddScalar", a.AddScalar(1), []float64{2, 3.5, math.NaN(), math.NaN(), math.NaN(), 7}},
+		{"SubScalar", a.SubScalar(1), []float64{0, 1.5, math.NaN(), math.NaN(), math.NaN(), 5}},
+		{"MulScalar", a.MulScalar(2), []float64{2, 5, math.NaN(), math.NaN(), math.NaN(), 12}},
+		{"DivScalar", a.DivScalar(0), []float64{inf, inf, math.NaN(), math.NaN(), math.NaN(), inf}},
+		{"DivScalar signs", NewSeries("c", []int{-1, 0}).DivScalar(0), []float64{math.Inf(-1), math.NaN()}},
+	} {
+		got := tc.got.Data()
+		if !slices.EqualFunc(got, tc.want, func(v any, want float64) bool {
+			f, ok := v.(float64)
+			return ok && (f == want || math.IsNaN(f) && math.IsNaN(want))
+		}) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("series of different lengths did not panic")
+		}
+	}()
+	a.Add(NewSeries("c", []int{1}))
+}
@@ -2,7 +2,12 @@ package df
 
 import (
 	"cmp"
+	"fmt"
 	"iter"
+	"log"
+	"math"
+	"slices"
+	"time"
 
 	"github.com/discoverkl/goterm/term"
 	"github.com/go-echarts/go-echarts/v2/charts"
@@ -19,10 +24,82 @@ type chartConfig struct {
 	xLabel string
 	yLabel string
 
+	// hide the title, axis labels, ticks and legend
+	minimal bool
+
+	// axis ticks
+	xTickFormat func(float64) string
+	yTickFormat func(float64) string
+	tickCount   int
+	locale      string
+
+	// fonts, the sizes are in points
+	titleFontSize float64
+	axisFontSize  float64
+	fontFamily    string
+
+	// for echarts
+	renderMode RenderMode
+
+	// chart type for Plot
+	kind string
+
 	// for gonum plot
 	ratio float64
 	plotX iter.Seq[float64]
 	lines []*LineData
+	dpi   int
+
+	// the styles of the lines by their names
+	lineStyles map[string]lineStyle
+
+	// layout of the x tick labels if x values are unix seconds
+	timeFormat string
+
+	// use a value axis for the x values of an echarts chart, instead of a category axis
+	xValueAxis bool
+
+	// how to draw the NaN and infinite points of a gonum chart
+	onInvalid InvalidMode
+
+	// the maximum number of points of a line of a gonum chart, 0 for no limit
+	maxPoints int
+
+	// draw curved lines instead of polylines
+	smooth bool
+	// draw staircase lines, "start", "middle" or "end"
+	step string
+
+	// the window of the moving averages of a line chart
+	movingAverage int
+
+	// embed the data in the output, see EmbedData
+	embedData bool
+
+	// reference lines
+	markLines []markLine
+
+	// filled areas between two lines
+	bands []*bandData
+}
+
+// lineStyle is the width and the dashes of a line, see LineStyle.
+type lineStyle struct {
+	width  float64
+	dashes []float64
+}
+
+// bandData is the area between the lower and upper lines.
+type bandData struct {
+	name         string
+	x            []float64
+	lower, upper []float64
+}
+
+// markLine is a horizontal reference line, such as a target or a mean.
+type markLine struct {
+	value float64
+	label string
 }
 
 type LineData struct {
@@ -30,6 +107,17 @@ type LineData struct {
 	X    []float64
 	Y    []float64
 	Fn   func(float64) float64
+
+	// YErr is the error of each point in Y, which is drawn as an error bar of y ± YErr
+	YErr []float64
+
+	// Scatter draws the points without connecting them
+	Scatter bool
+
+	// Width is the width of the line in points, 0 for the default width
+	Width float64
+	// Dashes are the lengths of the dashes and the gaps of the line in points, nil for a solid line
+	Dashes []float64
 }
 
 type ChartOption func(*chartConfig)
@@ -52,6 +140,111 @@ func YName(name string) ChartOption {
 	}
 }
 
+// Minimal hides the title, the axis names, the ticks and the legend, which leaves a clean small chart
+// for sparkline-style embedding. Combine it with Size for a compact chart in a dashboard grid.
+func Minimal() ChartOption {
+	return func(c *chartConfig) {
+		c.minimal = true
+	}
+}
+
+// XTickFormat formats the tick labels of the x axis, e.g. as a currency or a percentage.
+// For an echarts chart, it formats the labels of a numeric x column.
+func XTickFormat(format func(float64) string) ChartOption {
+	return func(c *chartConfig) {
+		c.xTickFormat = format
+	}
+}
+
+// YTickFormat formats the tick labels of the y axis, e.g. as a currency or a percentage.
+func YTickFormat(format func(float64) string) ChartOption {
+	return func(c *chartConfig) {
+		c.yTickFormat = format
+	}
+}
+
+// TickCount is a hint of the number of ticks on the value axes.
+func TickCount(n int) ChartOption {
+	return func(c *chartConfig) {
+		c.tickCount = n
+	}
+}
+
+// Locale formats the numbers of the tick labels and the tooltips by the conventions of a language tag,
+// such as "de" for 1.234,56. The XTickFormat and YTickFormat options take precedence for their axes.
+func Locale(tag string) ChartOption {
+	return func(c *chartConfig) {
+		c.locale = tag
+	}
+}
+
+// TitleFontSize sets the font size of the chart title, in points.
+func TitleFontSize(size float64) ChartOption {
+	return func(c *chartConfig) {
+		c.titleFontSize = size
+	}
+}
+
+// AxisFontSize sets the font size of the axis names and the tick labels, in points.
+func AxisFontSize(size float64) ChartOption {
+	return func(c *chartConfig) {
+		c.axisFontSize = size
+	}
+}
+
+// FontFamily sets the font family of the chart text. Echarts charts accept any CSS font family,
+// while gonum charts only support the generic families "serif", "sans-serif" and "monospace".
+func FontFamily(family string) ChartOption {
+	return func(c *chartConfig) {
+		c.fontFamily = family
+	}
+}
+
+// XValueAxis shows the first column of an echarts chart on a value axis, or a time axis if it holds times,
+// instead of a category axis. So the points are placed by their x values, which may be unevenly spaced.
+func XValueAxis() ChartOption {
+	return func(c *chartConfig) {
+		c.xValueAxis = true
+	}
+}
+
+// EChartMode sets the render mode of an echarts chart, instead of the default one set by EChartRenderMode.
+func EChartMode(mode RenderMode) ChartOption {
+	return func(c *chartConfig) {
+		c.renderMode = mode
+	}
+}
+
+// Kind sets the chart type which Plot draws, one of "bar", "line", "pie" and "xy".
+func Kind(kind string) ChartOption {
+	return func(c *chartConfig) {
+		c.kind = kind
+	}
+}
+
+// WithMovingAverage adds a dashed line of the moving average of each numeric column to a line chart,
+// named like "X (MA7)" for the column X and a window of 7, see Series.RollingMean.
+func WithMovingAverage(window int) ChartOption {
+	return func(c *chartConfig) {
+		c.movingAverage = window
+	}
+}
+
+// EmbedData adds the data of a DataFrame chart to the output, under the chart. It's in a JSON script element
+// for scripts, and in a link to download it as a CSV file, so the viewers of a report can get the raw numbers.
+func EmbedData() ChartOption {
+	return func(c *chartConfig) {
+		c.embedData = true
+	}
+}
+
+// MarkLine draws a horizontal reference line at y = value, such as a target threshold or a mean.
+func MarkLine(value float64, label string) ChartOption {
+	return func(c *chartConfig) {
+		c.markLines = append(c.markLines, markLine{value: value, label: label})
+	}
+}
+
 func LineFn(name string, fn func(float64) float64) ChartOption {
 	return func(c *chartConfig) {
 		c.lines = append(c.lines, &LineData{Name: name, Fn: fn})
@@ -64,6 +257,97 @@ func LineXY(name string, x, y []float64) ChartOption {
 	}
 }
 
+// ScatterXY draws the points of x and y as a scatter, without connecting them.
+func ScatterXY(name string, x, y []float64) ChartOption {
+	return func(c *chartConfig) {
+		c.lines = append(c.lines, &LineData{Name: name, X: x, Y: y, Scatter: true})
+	}
+}
+
+// LineXYErr is like LineXY, but draws an error bar of y ± yerr at each point.
+func LineXYErr(name string, x, y, yerr []float64) ChartOption {
+	return func(c *chartConfig) {
+		c.lines = append(c.lines, &LineData{Name: name, X: x, Y: y, YErr: yerr})
+	}
+}
+
+// InvalidMode is how a gonum chart handles the NaN and infinite points, see OnInvalid.
+type InvalidMode string
+
+const (
+	InvalidSkip  InvalidMode = "skip"  // Leave out the invalid points, and connect the points around them
+	InvalidBreak InvalidMode = "break" // Break the line at the invalid points, so that a gap shows the missing data
+	InvalidError InvalidMode = "error" // Return an error from the constructor of the chart
+)
+
+// OnInvalid sets how a gonum chart handles the NaN and infinite points, such as the missing values of a time series.
+// The default is InvalidSkip.
+func OnInvalid(mode InvalidMode) ChartOption {
+	return func(c *chartConfig) {
+		c.onInvalid = mode
+	}
+}
+
+// MaxPoints downsamples each line of a gonum chart to at most n points, which keeps the shape of the line,
+// so that a line of a million points doesn't make a huge SVG. About 2000 points are enough for a chart.
+// The first and the last points are always kept, so n is at least 3. The segments of a line which is broken
+// by the InvalidBreak mode share the n points in proportion to their sizes.
+func MaxPoints(n int) ChartOption {
+	return func(c *chartConfig) {
+		c.maxPoints = n
+	}
+}
+
+// Smooth draws the lines as smooth curves through the points, instead of straight segments.
+// The gonum charts interpolate the points with a Catmull-Rom spline.
+func Smooth() ChartOption {
+	return func(c *chartConfig) {
+		c.smooth = true
+	}
+}
+
+// Step draws the lines as staircases, which suit the values which change at discrete times, such as a state.
+// The position is where the step happens between two points: "start", "middle" or "end".
+// It takes precedence over Smooth, and panics if the position is not one of them.
+func Step(position string) ChartOption {
+	switch position {
+	case "start", "middle", "end":
+	default:
+		panic(fmt.Sprintf("invalid step position: %s", position))
+	}
+	return func(c *chartConfig) {
+		c.step = position
+	}
+}
+
+// LineStyle sets the width and the dashes of the line with the given name in a gonum chart, in points,
+// so that overlapping lines can be told apart without colors, such as in a grayscale printout.
+// For example, LineStyle("forecast", 2, 5, 3) draws a dashed line with 5pt dashes and 3pt gaps.
+// It takes precedence over the Width and the Dashes of the LineData.
+func LineStyle(name string, width float64, dashes ...float64) ChartOption {
+	return func(c *chartConfig) {
+		if c.lineStyles == nil {
+			c.lineStyles = map[string]lineStyle{}
+		}
+		c.lineStyles[name] = lineStyle{width: width, dashes: dashes}
+	}
+}
+
+// BandXY shades the area between the lower and upper lines, such as a confidence interval.
+// Add a center line with LineXY, which gets the same color as the band if they are added in the same order.
+func BandXY(name string, x, lower, upper []float64) ChartOption {
+	return func(c *chartConfig) {
+		c.bands = append(c.bands, &bandData{name: name, x: x, lower: lower, upper: upper})
+	}
+}
+
+// TimeX shows the x values of an XY chart, which are unix seconds, as times in the given layout.
+func TimeX(layout string) ChartOption {
+	return func(c *chartConfig) {
+		c.timeFormat = layout
+	}
+}
+
 func PlotX(x iter.Seq[float64]) ChartOption {
 	return func(c *chartConfig) {
 		c.plotX = x
@@ -83,6 +367,16 @@ func Ratio(ratio float64) ChartOption {
 	}
 }
 
+// DPI sets the dots per inch of the PNG image of a gonum chart, see XYChart.PNG. The default is DefaultDPI,
+// and 192 makes a sharp image on a high-DPI screen or in print. The size of the chart in inches doesn't
+// change, so the image is DefaultPlotHeight*n/96 pixels high. The SVG output, which is sized in CSS pixels,
+// is not affected.
+func DPI(n int) ChartOption {
+	return func(c *chartConfig) {
+		c.dpi = n
+	}
+}
+
 func (d *dataFrame) configEcharts(chart any, options ...ChartOption) *chartConfig {
 	c := &chartConfig{}
 	for _, option := range options {
@@ -93,74 +387,156 @@ func (d *dataFrame) configEcharts(chart any, options ...ChartOption) *chartConfi
 	xname := cmp.Or(c.xLabel, d.GetColumnAt(0).Name())
 	yname := c.yLabel
 
+	// The chart takes up the full width of its container, so that it fits a narrow window
+	initialization := charts.WithInitializationOpts(opts.Initialization{
+		Width: "100%",
+		Theme: echartTheme(),
+	})
+
+	title := opts.Title{Title: name}
+	if c.titleFontSize > 0 || c.fontFamily != "" {
+		title.TitleStyle = &opts.TextStyle{FontSize: int(c.titleFontSize), FontFamily: c.fontFamily}
+	}
+	var axisLabel *opts.AxisLabel
+	if c.axisFontSize > 0 || c.fontFamily != "" {
+		axisLabel = &opts.AxisLabel{FontSize: int(c.axisFontSize), FontFamily: c.fontFamily}
+	}
+	var axisTick *opts.AxisTick
+	low, high := d.valueRange()
+	legend := opts.Legend{}
+	tooltip := opts.Tooltip{}
+	if c.locale != "" {
+		tooltip.ValueFormatter = string(c.localeFormatter())
+	}
+	if c.minimal {
+		title = opts.Title{}
+		xname, yname = "", ""
+		axisLabel = &opts.AxisLabel{Show: opts.Bool(false)}
+		axisTick = &opts.AxisTick{Show: opts.Bool(false)}
+		legend.Show = opts.Bool(false)
+	}
+
+	xAxis := opts.XAxis{
+		Name:      xname,
+		AxisLabel: axisLabel,
+		AxisTick:  axisTick,
+	}
+	if c.xValueAxis {
+		xAxis = c.echartsXTicks(xAxis, d.GetColumnAt(0))
+	}
+
 	switch chart := chart.(type) {
 	case *charts.Bar:
 		chart.SetGlobalOptions(
-			charts.WithTitleOpts(opts.Title{
-				Title: name,
-			}),
-			charts.WithXAxisOpts(opts.XAxis{
-				Name: xname,
-			}),
-			charts.WithYAxisOpts(opts.YAxis{
-				Name: yname,
-			}),
+			initialization,
+			charts.WithTitleOpts(title),
+			charts.WithLegendOpts(legend),
+			charts.WithTooltipOpts(tooltip),
+			charts.WithXAxisOpts(xAxis),
+			charts.WithYAxisOpts(c.echartsYTicks(opts.YAxis{
+				Name:      yname,
+				AxisLabel: axisLabel,
+			}, low, high)),
 		)
 	case *charts.RectChart:
 		chart.SetGlobalOptions(
-			charts.WithTitleOpts(opts.Title{
-				Title: name,
-			}),
-			charts.WithXAxisOpts(opts.XAxis{
-				Name: xname,
-			}),
-			charts.WithYAxisOpts(opts.YAxis{
-				Name: yname,
-			}),
+			initialization,
+			charts.WithTitleOpts(title),
+			charts.WithLegendOpts(legend),
+			charts.WithTooltipOpts(tooltip),
+			charts.WithXAxisOpts(xAxis),
+			charts.WithYAxisOpts(c.echartsYTicks(opts.YAxis{
+				Name:      yname,
+				AxisLabel: axisLabel,
+			}, low, high)),
+		)
+	case *charts.Pie:
+		chart.SetGlobalOptions(
+			initialization,
+			charts.WithTitleOpts(title),
+			charts.WithLegendOpts(legend),
+			charts.WithTooltipOpts(tooltip),
 		)
 	}
 	return c
 }
 
+// valueRange returns the minimum and maximum of the values of the columns after the first one.
+func (d *dataFrame) valueRange() (low, high float64) {
+	low, high = math.Inf(1), math.Inf(-1)
+	for i := 1; i < len(d.Columns()); i++ {
+		s := d.GetColumnAt(i)
+		if !isNumeric(s) {
+			continue
+		}
+		for _, v := range s.ToFloat64() {
+			if !math.IsNaN(v) {
+				low, high = min(low, v), max(high, v)
+			}
+		}
+	}
+	return low, high
+}
+
 func (d *dataFrame) Bar(options ...ChartOption) {
 	bar := charts.NewBar()
 	c := d.configEcharts(&bar.RectChart, options...)
 
-	bar.SetXAxis(d.GetColumnAt(0).AsString())
+	if !c.xValueAxis {
+		bar.SetXAxis(c.xLabels(d.GetColumnAt(0)))
+	}
 	for i := 1; i < len(d.Columns()); i++ {
 		series := d.GetColumnAt(i)
 		var items []opts.BarData
-		for _, v := range series.Data() {
+		for _, v := range c.xyValues(d.GetColumnAt(0), series) {
 			items = append(items, opts.BarData{Value: v})
 		}
-		bar.AddSeries(series.Name(), items)
+		bar.AddSeries(series.Name(), items, c.seriesOpts(i)...)
 	}
 
-	d.printChart(NewEChart(bar), c)
+	d.printChart(NewEChart(bar).SetRenderMode(c.renderMode), c)
 }
 
 func (d *dataFrame) Line(options ...ChartOption) {
 	line := charts.NewLine()
 	c := d.configEcharts(&line.RectChart, options...)
 
-	line.SetXAxis(d.GetColumnAt(0).AsString())
+	if !c.xValueAxis {
+		line.SetXAxis(c.xLabels(d.GetColumnAt(0)))
+	}
 	for i := 1; i < len(d.Columns()); i++ {
 		series := d.GetColumnAt(i)
 		var items []opts.LineData
-		for _, v := range series.Data() {
+		for _, v := range c.xyValues(d.GetColumnAt(0), series) {
 			items = append(items, opts.LineData{Value: v})
 		}
-		line.AddSeries(series.Name(), items)
+		line.AddSeries(series.Name(), items, append(c.seriesOpts(i), c.lineOpts()...)...)
+	}
+	if c.movingAverage > 0 {
+		for i := 1; i < len(d.Columns()); i++ {
+			series := d.GetColumnAt(i)
+			switch firstValue(series.Data()).(type) {
+			case int, float64:
+			default:
+				continue
+			}
+			var items []opts.LineData
+			for _, v := range c.xyValues(d.GetColumnAt(0), series.RollingMean(c.movingAverage)) {
+				items = append(items, opts.LineData{Value: v})
+			}
+			line.AddSeries(fmt.Sprintf("%s (MA%d)", series.Name(), c.movingAverage), items,
+				append(c.lineOpts(), charts.WithLineStyleOpts(opts.LineStyle{Type: "dashed"}))...)
+		}
 	}
 
-	d.printChart(NewEChart(line), c)
+	d.printChart(NewEChart(line).SetRenderMode(c.renderMode), c)
 }
 
 func (d *dataFrame) Pie(options ...ChartOption) {
 	pie := charts.NewPie()
 	c := d.configEcharts(pie, options...)
 
-	names := d.GetColumnAt(0).AsString()
+	names := labels(d.GetColumnAt(0))
 	series := d.GetColumnAt(1)
 	var items []opts.PieData
 	for j, v := range series.Data() {
@@ -168,7 +544,42 @@ func (d *dataFrame) Pie(options ...ChartOption) {
 	}
 	pie.AddSeries(series.Name(), items)
 
-	d.printChart(NewEChart(pie), c)
+	d.printChart(NewEChart(pie).SetRenderMode(c.renderMode), c)
+}
+
+// Combo draws each column with its own chart type on one chart, such as bars for volume and a line for price.
+// The kinds map a column name to "bar" or "line", and the columns which are not in the map are drawn as bars.
+// All series share the x-axis of the first column.
+func (d *dataFrame) Combo(kinds map[string]string, options ...ChartOption) {
+	bar := charts.NewBar()
+	c := d.configEcharts(&bar.RectChart, options...)
+	line := charts.NewLine()
+
+	if !c.xValueAxis {
+		bar.SetXAxis(c.xLabels(d.GetColumnAt(0)))
+	}
+	for i := 1; i < len(d.Columns()); i++ {
+		series := d.GetColumnAt(i)
+		switch kind := cmp.Or(kinds[series.Name()], "bar"); kind {
+		case "bar":
+			var items []opts.BarData
+			for _, v := range c.xyValues(d.GetColumnAt(0), series) {
+				items = append(items, opts.BarData{Value: v})
+			}
+			bar.AddSeries(series.Name(), items, c.seriesOpts(i)...)
+		case "line":
+			var items []opts.LineData
+			for _, v := range c.xyValues(d.GetColumnAt(0), series) {
+				items = append(items, opts.LineData{Value: v})
+			}
+			line.AddSeries(series.Name(), items, append(c.seriesOpts(i), c.lineOpts()...)...)
+		default:
+			log.Printf("unsupported chart kind for combo: %s", kind)
+		}
+	}
+	bar.Overlap(line)
+
+	d.printChart(NewEChart(bar).SetRenderMode(c.renderMode), c)
 }
 
 func (d *dataFrame) XY(options ...ChartOption) {
@@ -177,6 +588,9 @@ func (d *dataFrame) XY(options ...ChartOption) {
 	}
 	x := d.GetColumnAt(0).ToFloat64()
 	chartOPs := []ChartOption{XName(d.GetColumnAt(0).Name())}
+	if isTime(d.GetColumnAt(0)) {
+		chartOPs = append(chartOPs, TimeX(timeLayout(d.GetColumnAt(0))))
+	}
 	for i, name := range d.Columns() {
 		if i == 0 {
 			continue
@@ -189,15 +603,122 @@ func (d *dataFrame) XY(options ...ChartOption) {
 	options = append(chartOPs, options...)
 	c, err := NewXYChart(options...)
 	if err != nil {
+		log.Printf("print plot failed: %v", err)
 		return
 	}
 	d.printChart(c, c.conf)
 }
 
+// maxPieSlices is the maximum number of rows which Plot draws as a pie chart.
+const maxPieSlices = 8
+
+// Plot draws the DataFrame with the chart type set by the Kind option, or picks one from the data:
+//   - a pie chart if there are two columns of labels and positive values, with no more than 8 rows
+//   - a line chart if the first column holds times
+//   - a bar chart if the first column is not numeric, such as strings
+//   - an XY chart if all columns are numeric
+func (d *dataFrame) Plot(options ...ChartOption) {
+	c := &chartConfig{}
+	for _, option := range options {
+		option(c)
+	}
+
+	switch kind := cmp.Or(c.kind, d.plotKind()); kind {
+	case "bar":
+		d.Bar(options...)
+	case "line":
+		d.Line(options...)
+	case "pie":
+		d.Pie(options...)
+	case "xy":
+		d.XY(options...)
+	case "":
+		// nothing to plot
+	default:
+		log.Printf("unknown chart kind: %s", kind)
+	}
+}
+
+// plotKind picks a chart type for the data, see Plot.
+func (d *dataFrame) plotKind() string {
+	if len(d.Columns()) < 2 {
+		return ""
+	}
+	if isTime(d.GetColumnAt(0)) {
+		return "line"
+	}
+	if isNumeric(d.GetColumnAt(0)) {
+		for i := 1; i < len(d.Columns()); i++ {
+			if !isNumeric(d.GetColumnAt(i)) {
+				return "bar"
+			}
+		}
+		return "xy"
+	}
+	if len(d.Columns()) == 2 && d.Rows() <= maxPieSlices && isNumeric(d.GetColumnAt(1)) &&
+		!slices.ContainsFunc(d.GetColumnAt(1).ToFloat64(), func(v float64) bool { return v <= 0 }) {
+		return "pie"
+	}
+	return "bar"
+}
+
+// isTime tells whether the series holds times.
+func isTime(s Series) bool {
+	return s.Len() > 0 && slices.IndexFunc(s.Data(), func(v any) bool {
+		_, ok := v.(time.Time)
+		return !ok && v != nil
+	}) == -1
+}
+
+// isNumeric tells whether all values of the series are numbers.
+func isNumeric(s Series) bool {
+	for _, v := range s.Data() {
+		switch v.(type) {
+		case float64, int:
+		default:
+			return false
+		}
+	}
+	return s.Len() > 0
+}
+
+// seriesOpts returns the options of the i-th column of an echarts chart.
+// The mark lines belong to the first series, so that they are drawn only once.
+func (c *chartConfig) seriesOpts(i int) []charts.SeriesOpts {
+	if i != 1 || len(c.markLines) == 0 {
+		return nil
+	}
+	var items []opts.MarkLineNameYAxisItem
+	for _, m := range c.markLines {
+		items = append(items, opts.MarkLineNameYAxisItem{Name: m.label, YAxis: m.value})
+	}
+	return []charts.SeriesOpts{
+		charts.WithMarkLineNameYAxisItemOpts(items...),
+		charts.WithMarkLineStyleOpts(opts.MarkLineStyle{
+			Label: &opts.Label{Show: opts.Bool(true), Formatter: "{b}: {c}"},
+		}),
+	}
+}
+
+// lineOpts returns the options of the line series of an echarts chart.
+func (c *chartConfig) lineOpts() []charts.SeriesOpts {
+	if !c.smooth && c.step == "" {
+		return nil
+	}
+	var step any
+	if c.step != "" {
+		step = c.step
+	}
+	return []charts.SeriesOpts{charts.WithLineChartOpts(opts.LineChart{Smooth: opts.Bool(c.smooth), Step: step})}
+}
+
 func (d *dataFrame) printChart(chart term.BlockElement, c *chartConfig) {
 	ops := []term.BlockOption{}
 	if c.width != 0 || c.height != 0 {
 		ops = append(ops, term.SizeOption(c.width, c.height))
 	}
 	term.Block(chart, ops...)
+	if c.embedData {
+		term.PrintHtml(dataHTML(d))
+	}
 }
@@ -0,0 +1,994 @@
+package df
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query runs a SQL SELECT statement over the DataFrames, which are the tables of the statement by their names.
+// It supports a subset of SQL:
+//
+//	SELECT *, column [AS alias], COUNT(*), SUM(column), AVG(column), MIN(column), MAX(column)
+//	FROM table
+//	WHERE condition
+//	GROUP BY column, ...
+//	ORDER BY column [ASC | DESC], ...
+//	LIMIT n
+//
+// A condition compares columns and literals with =, !=, <>, <, <=, > and >=, IS [NOT] NULL, [NOT] IN (...)
+// and [NOT] LIKE, combined with AND, OR, NOT and parentheses. A time is compared with a string in the
+// formats of RFC 3339, "2006-01-02 15:04:05" or "2006-01-02", and the values of other different kinds,
+// such as a number and a string, can't be compared. The names of the columns and the tables
+// which are not plain words are quoted with double quotes or backquotes, and strings with single quotes.
+//
+// Null values don't match any comparison, and they are sorted last. Without GROUP BY, ORDER BY can use
+// the columns of the table which are not selected. With GROUP BY or an aggregate, it uses the selected columns.
+// Numbers of a GROUP BY column are grouped by value, so the int 1 and the float64 1.0 are in the same group.
+// It returns an error for the unsupported syntax, such as joins, for the tables and columns not found,
+// and for the comparisons of values which can't be compared.
+func Query(sql string, frames map[string]DataFrame) (DataFrame, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	d := frames[q.table]
+	if d == nil {
+		return nil, fmt.Errorf("table not found: %s", q.table)
+	}
+	columns := map[string][]any{}
+	for _, name := range d.Columns() {
+		columns[name] = d.GetColumn(name).Data()
+	}
+	for _, name := range p.refs {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("column not found: %s", name)
+		}
+	}
+
+	// Filter the rows
+	var rows []int
+	for i := 0; i < d.Rows(); i++ {
+		if q.where == nil {
+			rows = append(rows, i)
+			continue
+		}
+		v, err := q.where.eval(columns, i)
+		if err != nil {
+			return nil, err
+		}
+		if v == true {
+			rows = append(rows, i)
+		}
+	}
+
+	if q.grouped() {
+		return q.aggregate(columns, rows)
+	}
+	return q.project(d, columns, rows)
+}
+
+// query is a parsed SELECT statement.
+type query struct {
+	items   []selectItem
+	table   string
+	where   expr
+	groupBy []string
+	orderBy []orderItem
+	limit   int // -1 for no limit
+}
+
+// selectItem is a column of the result: all columns for *, a column, or an aggregate of a column.
+type selectItem struct {
+	star   bool
+	column string // "*" for COUNT(*)
+	agg    string // the lower case name of the aggregate function, empty for a column
+	alias  string
+}
+
+// name returns the name of the result column.
+func (item selectItem) name() string {
+	if item.alias != "" {
+		return item.alias
+	}
+	if item.agg != "" {
+		return fmt.Sprintf("%s(%s)", item.agg, item.column)
+	}
+	return item.column
+}
+
+type orderItem struct {
+	name string
+	desc bool
+}
+
+// grouped tells whether the rows are aggregated into groups.
+func (q *query) grouped() bool {
+	return len(q.groupBy) > 0 || slices.ContainsFunc(q.items, func(item selectItem) bool { return item.agg != "" })
+}
+
+// project selects the columns of the rows, for a query without aggregates.
+func (q *query) project(d DataFrame, columns map[string][]any, rows []int) (DataFrame, error) {
+	var items []selectItem
+	for _, item := range q.items {
+		if item.star {
+			for _, name := range d.Columns() {
+				items = append(items, selectItem{column: name})
+			}
+		} else {
+			items = append(items, item)
+		}
+	}
+
+	// The order uses the name of a result column, or a column of the table
+	var keys [][]any
+	for _, o := range q.orderBy {
+		name := o.name
+		if i := slices.IndexFunc(items, func(item selectItem) bool { return item.name() == name }); i >= 0 {
+			name = items[i].column
+		}
+		data, ok := columns[name]
+		if !ok {
+			return nil, fmt.Errorf("column not found: %s", o.name)
+		}
+		keys = append(keys, data)
+	}
+	slices.SortStableFunc(rows, func(a, b int) int {
+		for k, o := range q.orderBy {
+			if c := compareOrder(keys[k][a], keys[k][b], o.desc); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	rows = limitRows(rows, q.limit)
+
+	var result []Series
+	for _, item := range items {
+		data := make([]any, len(rows))
+		for j, i := range rows {
+			data[j] = columns[item.column][i]
+		}
+		result = append(result, &series{name: item.name(), data: data})
+	}
+	return newResult(result)
+}
+
+// aggregate computes a row for each group of the rows, for a query with GROUP BY or aggregates.
+func (q *query) aggregate(columns map[string][]any, rows []int) (DataFrame, error) {
+	for _, item := range q.items {
+		if item.star {
+			return nil, fmt.Errorf("unsupported syntax: * with GROUP BY or an aggregate")
+		}
+		if item.agg == "" && !slices.Contains(q.groupBy, item.column) {
+			return nil, fmt.Errorf("column %s must be in GROUP BY", item.column)
+		}
+	}
+
+	// Group the rows by their keys, in the order of the first row of each group
+	type group struct {
+		rows []int
+	}
+	var groups []*group
+	if len(q.groupBy) == 0 {
+		// All rows are one group, even if there is no row
+		groups = append(groups, &group{rows: rows})
+	} else {
+		index := map[string]*group{}
+		for _, i := range rows {
+			var key strings.Builder
+			for _, name := range q.groupBy {
+				// A whole float64 is an int, so that the numbers of the same value are one group
+				v := columns[name][i]
+				if k, ok := mergeKey(v); ok {
+					v = k
+				}
+				fmt.Fprintf(&key, "%T:%v\x00", v, v)
+			}
+			g := index[key.String()]
+			if g == nil {
+				g = &group{}
+				index[key.String()] = g
+				groups = append(groups, g)
+			}
+			g.rows = append(g.rows, i)
+		}
+	}
+
+	var table [][]any
+	for _, g := range groups {
+		row := make([]any, len(q.items))
+		for j, item := range q.items {
+			if item.agg == "" {
+				row[j] = columns[item.column][g.rows[0]]
+				continue
+			}
+			var values []any
+			if item.column != "*" {
+				for _, i := range g.rows {
+					values = append(values, columns[item.column][i])
+				}
+			}
+			v, err := aggregateValues(item, values, len(g.rows))
+			if err != nil {
+				return nil, err
+			}
+			row[j] = v
+		}
+		table = append(table, row)
+	}
+
+	// The order uses the name of a result column
+	var keys []int
+	for _, o := range q.orderBy {
+		k := slices.IndexFunc(q.items, func(item selectItem) bool { return item.name() == o.name })
+		if k < 0 {
+			return nil, fmt.Errorf("column not found: %s", o.name)
+		}
+		keys = append(keys, k)
+	}
+	slices.SortStableFunc(table, func(a, b []any) int {
+		for k, o := range q.orderBy {
+			if c := compareOrder(a[keys[k]], b[keys[k]], o.desc); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	table = limitRows(table, q.limit)
+
+	var result []Series
+	for j, item := range q.items {
+		data := make([]any, len(table))
+		for i, row := range table {
+			data[i] = row[j]
+		}
+		result = append(result, &series{name: item.name(), data: data})
+	}
+	return newResult(result)
+}
+
+// limitRows returns the first rows up to the limit, or all rows if the limit is -1.
+func limitRows[T any](rows []T, limit int) []T {
+	if limit >= 0 && limit < len(rows) {
+		return rows[:limit]
+	}
+	return rows
+}
+
+// newResult returns a DataFrame of the result columns, which must have different names.
+func newResult(columns []Series) (DataFrame, error) {
+	var names []string
+	for _, s := range columns {
+		if contains(names, s.Name()) {
+			return nil, fmt.Errorf("duplicate column: %s", s.Name())
+		}
+		names = append(names, s.Name())
+	}
+	return NewDataFrame(columns...), nil
+}
+
+// aggregateValues returns the aggregate of the values of a group of n rows. Null values are ignored,
+// and the aggregate of no value is null, except for a count. The sum of int values is an int, which is
+// added up as an int64.
+func aggregateValues(item selectItem, values []any, n int) (any, error) {
+	values = slices.DeleteFunc(values, func(v any) bool { return v == nil })
+	switch item.agg {
+	case "count":
+		if item.column == "*" {
+			return n, nil
+		}
+		return len(values), nil
+	case "sum", "avg":
+		if len(values) == 0 {
+			return nil, nil
+		}
+		var isum int64
+		var fsum float64
+		ints := true
+		for _, v := range values {
+			switch v := v.(type) {
+			case int:
+				isum += int64(v)
+			case float64:
+				fsum += v
+				ints = false
+			default:
+				return nil, fmt.Errorf("%s of a non-numeric column: %s", item.agg, item.column)
+			}
+		}
+		sum := float64(isum) + fsum
+		if item.agg == "avg" {
+			return sum / float64(len(values)), nil
+		}
+		if ints {
+			return int(isum), nil
+		}
+		return sum, nil
+	case "min", "max":
+		var ret any
+		for _, v := range values {
+			c, ok := compareCells(v, ret)
+			if ret == nil || ok && (item.agg == "min" && c < 0 || item.agg == "max" && c > 0) {
+				ret = v
+			}
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("unsupported function: %s", item.agg)
+}
+
+// compareOrder compares two values for ORDER BY, with the null values last in both directions.
+func compareOrder(a, b any, desc bool) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	c, _ := compareCells(a, b)
+	if desc {
+		return -c
+	}
+	return c
+}
+
+// compareCells compares two non-null values of the same kind: numbers, strings, bools, or times,
+// which can also be compared with strings. It returns false if they can't be compared.
+func compareCells(a, b any) (int, bool) {
+	if s, ok := a.(string); ok {
+		if _, ok := b.(time.Time); ok {
+			c, ok := compareCells(b, s)
+			return -c, ok
+		}
+	}
+	switch a := a.(type) {
+	case time.Time:
+		switch b := b.(type) {
+		case time.Time:
+			return a.Compare(b), true
+		case string:
+			for _, layout := range timeLayouts {
+				if t, err := time.ParseInLocation(layout, b, a.Location()); err == nil {
+					return a.Compare(t), true
+				}
+			}
+		}
+		return 0, false
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+		return 0, false
+	case bool:
+		if b, ok := b.(bool); ok {
+			return cmp.Compare(boolNumber(a), boolNumber(b)), true
+		}
+		return 0, false
+	}
+	if _, ok := b.(time.Time); ok {
+		return 0, false
+	}
+	x, okx := toNumber(a)
+	y, oky := toNumber(b)
+	if !okx || !oky {
+		return 0, false
+	}
+	return cmp.Compare(x, y), true
+}
+
+func boolNumber(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// expr is a condition of WHERE, or an operand of a condition. A condition is true, false or nil,
+// which is the unknown result of a comparison with a null value. The error is a comparison of values
+// which can't be compared.
+type expr interface {
+	eval(columns map[string][]any, i int) (any, error)
+}
+
+type columnExpr string
+
+func (e columnExpr) eval(columns map[string][]any, i int) (any, error) {
+	return columns[string(e)][i], nil
+}
+
+type literalExpr struct {
+	v any
+}
+
+func (e literalExpr) eval(map[string][]any, int) (any, error) {
+	return e.v, nil
+}
+
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e compareExpr) eval(columns map[string][]any, i int) (any, error) {
+	a, b, err := evalBoth(e.left, e.right, columns, i)
+	if err != nil || a == nil || b == nil {
+		return nil, err
+	}
+	c, ok := compareCells(a, b)
+	if !ok {
+		return nil, fmt.Errorf("can't compare %T %v with %T %v", a, a, b, b)
+	}
+	switch e.op {
+	case "=":
+		return c == 0, nil
+	case "!=", "<>":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default: // ">="
+		return c >= 0, nil
+	}
+}
+
+// evalBoth evaluates two expressions of a row.
+func evalBoth(left, right expr, columns map[string][]any, i int) (any, any, error) {
+	a, err := left.eval(columns, i)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := right.eval(columns, i)
+	return a, b, err
+}
+
+// logicExpr is AND or OR, with the logic of SQL for the unknown results.
+type logicExpr struct {
+	and         bool
+	left, right expr
+}
+
+func (e logicExpr) eval(columns map[string][]any, i int) (any, error) {
+	a, b, err := evalBoth(e.left, e.right, columns, i)
+	if err != nil {
+		return nil, err
+	}
+	// false decides an AND, and true decides an OR
+	decisive := !e.and
+	if a == decisive || b == decisive {
+		return decisive, nil
+	}
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	return !decisive, nil
+}
+
+type notExpr struct {
+	e expr
+}
+
+func (e notExpr) eval(columns map[string][]any, i int) (any, error) {
+	v, err := e.e.eval(columns, i)
+	if b, ok := v.(bool); ok {
+		return !b, nil
+	}
+	return nil, err
+}
+
+type isNullExpr struct {
+	e   expr
+	not bool
+}
+
+func (e isNullExpr) eval(columns map[string][]any, i int) (any, error) {
+	v, err := e.e.eval(columns, i)
+	return (v == nil) != e.not, err
+}
+
+type inExpr struct {
+	e    expr
+	list []expr
+	not  bool
+}
+
+func (e inExpr) eval(columns map[string][]any, i int) (any, error) {
+	// The value is in the list if it equals an item, and it's unknown if it's compared with a null value
+	var in any = false
+	for _, item := range e.list {
+		eq, err := compareExpr{op: "=", left: e.e, right: item}.eval(columns, i)
+		if err != nil {
+			return nil, err
+		}
+		if eq == true {
+			in = true
+			break
+		}
+		if eq == nil {
+			in = nil
+		}
+	}
+	if b, ok := in.(bool); ok {
+		return b != e.not, nil
+	}
+	return nil, nil
+}
+
+type likeExpr struct {
+	e   expr
+	re  *regexp.Regexp
+	not bool
+}
+
+func (e likeExpr) eval(columns map[string][]any, i int) (any, error) {
+	v, err := e.e.eval(columns, i)
+	s, ok := v.(string)
+	if !ok {
+		return nil, err
+	}
+	return e.re.MatchString(s) != e.not, nil
+}
+
+// likePattern converts a LIKE pattern, where % matches any string and _ matches any character, to a regexp.
+func likePattern(pattern string) *regexp.Regexp {
+	var buf strings.Builder
+	buf.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			buf.WriteString(".*")
+		case '_':
+			buf.WriteString(".")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.MustCompile(buf.String())
+}
+
+type tokenKind int
+
+const (
+	identToken  tokenKind = iota // A word, or a quoted name
+	stringToken                  // A string in single quotes
+	numberToken
+	symbolToken // An operator, a parenthesis, a comma or *
+	endToken
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	quoted bool // a quoted name, which is never a keyword
+}
+
+// tokenize splits a SQL statement into tokens, the last of which is an endToken.
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"' || r == '`':
+			// A quote in a string or a name is escaped by doubling it
+			var text strings.Builder
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == r {
+					if j+1 < len(runes) && runes[j+1] == r {
+						j++
+					} else {
+						break
+					}
+				}
+				text.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated quote at %d", i)
+			}
+			if r == '\'' {
+				tokens = append(tokens, token{kind: stringToken, text: text.String()})
+			} else {
+				tokens = append(tokens, token{kind: identToken, text: text.String(), quoted: true})
+			}
+			i = j + 1
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || strings.ContainsRune(".eE", runes[j]) ||
+				strings.ContainsRune("+-", runes[j]) && strings.ContainsRune("eE", runes[j-1])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: numberToken, text: string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{kind: identToken, text: string(runes[i:j])})
+			i = j
+		default:
+			text := string(r)
+			if i+1 < len(runes) && slices.Contains([]string{"!=", "<>", "<=", ">="}, string(runes[i:i+2])) {
+				text = string(runes[i : i+2])
+			} else if !strings.ContainsRune("=<>(),*-", r) {
+				return nil, fmt.Errorf("unexpected character %q at %d", r, i)
+			}
+			tokens = append(tokens, token{kind: symbolToken, text: text})
+			i += len([]rune(text))
+		}
+	}
+	return append(tokens, token{kind: endToken}), nil
+}
+
+// keywords are the words which are not names unless they are quoted.
+var keywords = []string{
+	"SELECT", "FROM", "WHERE", "GROUP", "BY", "ORDER", "LIMIT", "AS", "ASC", "DESC",
+	"AND", "OR", "NOT", "IS", "NULL", "IN", "LIKE", "TRUE", "FALSE",
+	"JOIN", "ON", "HAVING", "UNION", "DISTINCT", "OFFSET",
+}
+
+// aggregates are the supported aggregate functions.
+var aggregates = []string{"count", "sum", "avg", "min", "max"}
+
+// parser parses the tokens of a SELECT statement.
+type parser struct {
+	tokens []token
+	pos    int
+
+	// refs are the names of the columns of the table which are used
+	refs []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != endToken {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword tells whether the token is the given keyword.
+func isKeyword(t token, keyword string) bool {
+	return t.kind == identToken && !t.quoted && strings.EqualFold(t.text, keyword)
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if isKeyword(p.peek(), keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	if t := p.peek(); t.kind == symbolToken && t.text == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+// unexpected returns the error of an unsupported token.
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == endToken {
+		return fmt.Errorf("unsupported syntax: unexpected end")
+	}
+	return fmt.Errorf("unsupported syntax near %q", t.text)
+}
+
+// name parses a name, which is a word which is not a keyword, or a quoted name.
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if t.kind != identToken || !t.quoted && slices.ContainsFunc(keywords, func(k string) bool { return isKeyword(t, k) }) {
+		return "", p.unexpected()
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// column parses the name of a column of the table.
+func (p *parser) column() (string, error) {
+	name, err := p.name()
+	if err == nil {
+		p.refs = append(p.refs, name)
+	}
+	return name, err
+}
+
+func (p *parser) parseQuery() (*query, error) {
+	q := &query{limit: -1}
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		q.items = append(q.items, item)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	q.table = table
+
+	if p.acceptKeyword("WHERE") {
+		if q.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			name, err := p.column()
+			if err != nil {
+				return nil, err
+			}
+			q.groupBy = append(q.groupBy, name)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			// The name may be an alias, which is checked later
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			o := orderItem{name: name}
+			if p.acceptKeyword("DESC") {
+				o.desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			q.orderBy = append(q.orderBy, o)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		t := p.next()
+		n, err := strconv.Atoi(t.text)
+		if t.kind != numberToken || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid limit: %s", t.text)
+		}
+		q.limit = n
+	}
+	if p.peek().kind != endToken {
+		return nil, p.unexpected()
+	}
+	return q, nil
+}
+
+func (p *parser) parseSelectItem() (selectItem, error) {
+	if p.acceptSymbol("*") {
+		return selectItem{star: true}, nil
+	}
+
+	var item selectItem
+	t := p.peek()
+	if fn := strings.ToLower(t.text); t.kind == identToken && !t.quoted && slices.Contains(aggregates, fn) &&
+		p.tokens[p.pos+1].kind == symbolToken && p.tokens[p.pos+1].text == "(" {
+		p.pos += 2
+		item.agg = fn
+		if fn == "count" && p.acceptSymbol("*") {
+			item.column = "*"
+		} else {
+			name, err := p.column()
+			if err != nil {
+				return item, err
+			}
+			item.column = name
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return item, err
+		}
+	} else {
+		name, err := p.column()
+		if err != nil {
+			return item, err
+		}
+		item.column = name
+	}
+
+	// The alias may follow AS, or directly the column
+	if p.acceptKeyword("AS") {
+		alias, err := p.name()
+		if err != nil {
+			return item, err
+		}
+		item.alias = alias
+	} else if alias, err := p.name(); err == nil {
+		item.alias = alias
+	}
+	return item, nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicExpr{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicExpr{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.acceptKeyword("NOT") {
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{e}, nil
+	}
+	return p.parseCondition()
+}
+
+// parseCondition parses a condition in parentheses, or a comparison of an operand.
+func (p *parser) parseCondition() (expr, error) {
+	if p.acceptSymbol("(") {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expectSymbol(")")
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == symbolToken && slices.Contains([]string{"=", "!=", "<>", "<", "<=", ">", ">="}, t.text) {
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareExpr{op: t.text, left: left, right: right}, nil
+	}
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		return isNullExpr{e: left, not: not}, p.expectKeyword("NULL")
+	}
+	not := p.acceptKeyword("NOT")
+	switch {
+	case p.acceptKeyword("IN"):
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		e := inExpr{e: left, not: not}
+		for {
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			e.list = append(e.list, item)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		return e, p.expectSymbol(")")
+	case p.acceptKeyword("LIKE"):
+		t := p.next()
+		if t.kind != stringToken {
+			return nil, fmt.Errorf("unsupported syntax: LIKE needs a string pattern")
+		}
+		return likeExpr{e: left, re: likePattern(t.text), not: not}, nil
+	}
+	return nil, p.unexpected()
+}
+
+// parseOperand parses a column or a literal.
+func (p *parser) parseOperand() (expr, error) {
+	negative := p.acceptSymbol("-")
+	t := p.peek()
+	if negative && t.kind != numberToken {
+		return nil, p.unexpected()
+	}
+	switch {
+	case t.kind == numberToken:
+		p.pos++
+		text := t.text
+		if negative {
+			text = "-" + text
+		}
+		if n, err := strconv.Atoi(text); err == nil {
+			return literalExpr{n}, nil
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %s", t.text)
+		}
+		return literalExpr{f}, nil
+	case t.kind == stringToken:
+		p.pos++
+		return literalExpr{t.text}, nil
+	case p.acceptKeyword("TRUE"):
+		return literalExpr{true}, nil
+	case p.acceptKeyword("FALSE"):
+		return literalExpr{false}, nil
+	case p.acceptKeyword("NULL"):
+		return literalExpr{nil}, nil
+	}
+	name, err := p.column()
+	if err != nil {
+		return nil, err
+	}
+	return columnExpr(name), nil
+}
@@ -0,0 +1,153 @@
+package df
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func queryFrames() map[string]DataFrame {
+	sales := NewDataFrame(
+		NewSeries("region", []string{"east", "west", "east", "north", "west"}),
+		NewSeries("units", []int{3, 5, 2, 7, 1}),
+		NewSeries("price", []float64{1.5, 2, 2.5, 1, 4}),
+		NewTimeSeries("day", []time.Time{
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		}),
+		NewSeriesAny("note", []any{"a", nil, "b", nil, "c"}),
+	)
+	return map[string]DataFrame{"sales": sales}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		sql     string
+		columns []string
+		want    [][]any // the rows of the result
+	}{
+		{"SELECT * FROM sales LIMIT 1", []string{"region", "units", "price", "day", "note"},
+			[][]any{{"east", 3, 1.5, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "a"}}},
+		{"SELECT region, units AS n FROM sales WHERE units > 2 AND price < 2", []string{"region", "n"},
+			[][]any{{"east", 3}, {"north", 7}}},
+		{"SELECT region FROM sales WHERE region = 'west' OR NOT (units >= 3)", []string{"region"},
+			[][]any{{"west"}, {"east"}, {"west"}}},
+		{"SELECT units FROM sales WHERE note IS NULL", []string{"units"}, [][]any{{5}, {7}}},
+		{"SELECT units FROM sales WHERE note IS NOT NULL AND note != 'b'", []string{"units"}, [][]any{{3}, {1}}},
+		{"SELECT units FROM sales WHERE region IN ('north', 'west') AND region NOT LIKE 'w%'", []string{"units"},
+			[][]any{{7}}},
+		{"SELECT units FROM sales WHERE day >= '2024-01-04'", []string{"units"}, [][]any{{7}, {1}}},
+		{"SELECT units FROM sales WHERE price = 2", []string{"units"}, [][]any{{5}}},
+		{"SELECT units FROM sales WHERE units <> -1 AND units < 2.5", []string{"units"}, [][]any{{2}, {1}}},
+		// Null values don't match, even with NOT
+		{"SELECT units FROM sales WHERE NOT note = 'a'", []string{"units"}, [][]any{{2}, {1}}},
+		{"SELECT region, units FROM sales ORDER BY region, units DESC LIMIT 3", []string{"region", "units"},
+			[][]any{{"east", 3}, {"east", 2}, {"north", 7}}},
+		{"SELECT units FROM sales ORDER BY price DESC", []string{"units"}, [][]any{{1}, {2}, {5}, {3}, {7}}},
+		{"SELECT units FROM sales ORDER BY note", []string{"units"}, [][]any{{3}, {2}, {1}, {5}, {7}}},
+		{"SELECT units FROM sales LIMIT 0", []string{"units"}, nil},
+		{"SELECT COUNT(*), COUNT(note), SUM(units), AVG(units), MIN(price), MAX(region) FROM sales",
+			[]string{"count(*)", "count(note)", "sum(units)", "avg(units)", "min(price)", "max(region)"},
+			[][]any{{5, 3, 18, 3.6, 1.0, "west"}}},
+		{"SELECT COUNT(*) AS n, SUM(units) FROM sales WHERE units > 100", []string{"n", "sum(units)"},
+			[][]any{{0, nil}}},
+		{"SELECT region, SUM(units) AS total, SUM(price) FROM sales GROUP BY region ORDER BY total DESC",
+			[]string{"region", "total", "sum(price)"},
+			[][]any{{"north", 7, 1.0}, {"west", 6, 6.0}, {"east", 5, 4.0}}},
+		{"SELECT region, COUNT(*) FROM sales GROUP BY region LIMIT 2", []string{"region", "count(*)"},
+			[][]any{{"east", 2}, {"west", 2}}},
+		{`SELECT "region" FROM "sales" WHERE "units" = 1`, []string{"region"}, [][]any{{"west"}}},
+	}
+	for _, tt := range tests {
+		r, err := Query(tt.sql, queryFrames())
+		if err != nil {
+			t.Errorf("%s: %v", tt.sql, err)
+			continue
+		}
+		if got := r.Columns(); !slices.Equal(got, tt.columns) {
+			t.Errorf("%s: got columns %v, want %v", tt.sql, got, tt.columns)
+			continue
+		}
+		var rows [][]any
+		for i := 0; i < r.Rows(); i++ {
+			var row []any
+			for _, name := range r.Columns() {
+				row = append(row, r.GetColumn(name).Data()[i])
+			}
+			rows = append(rows, row)
+		}
+		if !slices.EqualFunc(rows, tt.want, slices.Equal) {
+			t.Errorf("%s: got %v, want %v", tt.sql, rows, tt.want)
+		}
+	}
+}
+
+func TestQueryGroupNumbers(t *testing.T) {
+	// The int and the float64 of the same value are one group
+	d := NewDataFrame(NewSeriesAny("k", []any{1, 1.0, 2, 1.5}), NewSeries("v", []int{1, 2, 3, 4}))
+	r, err := Query("SELECT k, SUM(v) FROM t GROUP BY k", map[string]DataFrame{"t": d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.GetColumn("sum(v)").Data(), []any{3, 3, 4}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestQuerySumInt(t *testing.T) {
+	// The sum of big ints is exact, which it wouldn't be as a float64
+	d := NewDataFrame(NewSeries("v", []int{math.MaxInt64 - 1, 1, -1}))
+	r, err := Query("SELECT SUM(v) FROM t", map[string]DataFrame{"t": d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.GetColumn("sum(v)").Data()[0]; got != math.MaxInt64-1 {
+		t.Errorf("got %v, want %d", got, math.MaxInt64-1)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT units FROM sales WHERE units = 'x'", "can't compare"},
+		{"SELECT units FROM sales WHERE 'x' < price", "can't compare"},
+		{"SELECT units FROM sales WHERE day = 'tomorrow'", "can't compare"},
+		{"SELECT units FROM sales WHERE region IN ('east', 1)", "can't compare"},
+		{"SELECT units FROM sales WHERE note IS NULL OR units = TRUE", "can't compare"},
+		{"SELECT units FROM orders", "table not found: orders"},
+		{"SELECT amount FROM sales", "column not found: amount"},
+		{"SELECT units FROM sales WHERE amount > 1", "column not found: amount"},
+		{"SELECT units FROM sales ORDER BY amount", "column not found: amount"},
+		{"SELECT region, SUM(units) FROM sales GROUP BY region ORDER BY units", "column not found: units"},
+		{"SELECT region, units FROM sales GROUP BY region", "column units must be in GROUP BY"},
+		{"SELECT *, COUNT(*) FROM sales", "unsupported syntax: * with GROUP BY"},
+		{"SELECT SUM(region) FROM sales", "sum of a non-numeric column: region"},
+		{"SELECT units, units FROM sales", "duplicate column: units"},
+		{"SELECT units FROM sales LIMIT -1", "invalid limit"},
+		{"SELECT units FROM sales LIMIT x", "invalid limit"},
+		{"SELECT units FROM sales WHERE region LIKE region", "LIKE needs a string pattern"},
+		{"SELECT units FROM sales WHERE region = 'x", "unterminated quote"},
+		{"SELECT units FROM sales WHERE units ; 1", "unexpected character"},
+		{"SELECT units FROM sales WHERE units = 1e", "invalid number"},
+		{"SELECT units FROM sales JOIN other ON units", `unsupported syntax near "JOIN"`},
+		{"SELECT units FROM sales WHERE units", "unsupported syntax: unexpected end"},
+		{"SELECT units FROM sales WHERE units = -'x'", `unsupported syntax near "x"`},
+		{"UPDATE sales", `unsupported syntax near "UPDATE"`},
+		{"SELECT FROM sales", `unsupported syntax near "FROM"`},
+		{"SELECT units FROM sales WHERE (units = 1", "unsupported syntax: unexpected end"},
+		{"SELECT units FROM sales ORDER units", `unsupported syntax near "units"`},
+	}
+	for _, tt := range tests {
+		_, err := Query(tt.sql, queryFrames())
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want %q", tt.sql, err, tt.want)
+		}
+	}
+}
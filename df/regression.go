@@ -0,0 +1,151 @@
+package df
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"slices"
+)
+
+// LinearFit fits the line y = slope*x + intercept by ordinary least squares, and returns
+// the coefficient of determination R² of the fit. The pairs with a NaN value are ignored.
+// It returns NaN values if there are less than two distinct x values.
+func LinearFit(x, y []float64) (slope, intercept, r2 float64) {
+	xs, ys := validPairs(x, y)
+	nan := math.NaN()
+	if len(xs) < 2 {
+		return nan, nan, nan
+	}
+
+	mx, my := Avg(xs), Avg(ys)
+	var sxx, sxy, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return nan, nan, nan
+	}
+	slope = sxy / sxx
+	intercept = my - slope*mx
+	r2 = 1.0
+	if syy != 0 {
+		r2 = sxy * sxy / (sxx * syy)
+	}
+	return slope, intercept, r2
+}
+
+// PolyFit fits the polynomial of the degree by least squares, and returns its coefficients from the
+// constant term up, so y = c[0] + c[1]*x + c[2]*x² + ... The pairs with a NaN value are ignored.
+// It returns an error if the degree is negative, or there are not enough distinct x values for it.
+func PolyFit(x, y []float64, degree int) ([]float64, error) {
+	if degree < 0 {
+		return nil, fmt.Errorf("invalid degree: %d", degree)
+	}
+	xs, ys := validPairs(x, y)
+	n := degree + 1
+	if len(xs) < n {
+		return nil, fmt.Errorf("%d points are not enough for degree %d", len(xs), degree)
+	}
+
+	// The normal equations A c = b, where A[j][k] = Σ x^(j+k) and b[j] = Σ x^j y
+	a := make([][]float64, n)
+	for j := range a {
+		a[j] = make([]float64, n+1)
+	}
+	for i := range xs {
+		p := 1.0
+		powers := make([]float64, 2*n-1)
+		for k := range powers {
+			powers[k] = p
+			p *= xs[i]
+		}
+		for j := range n {
+			for k := range n {
+				a[j][k] += powers[j+k]
+			}
+			a[j][n] += powers[j] * ys[i]
+		}
+	}
+	return solve(a)
+}
+
+// solve solves the linear equations of the augmented matrix by Gaussian elimination with partial pivoting.
+func solve(a [][]float64) ([]float64, error) {
+	n := len(a)
+	for col := range n {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, fmt.Errorf("singular matrix")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for row := col + 1; row < n; row++ {
+			f := a[row][col] / a[col][col]
+			for k := col; k <= n; k++ {
+				a[row][k] -= f * a[col][k]
+			}
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		v := a[row][n]
+		for k := row + 1; k < n; k++ {
+			v -= a[row][k] * x[k]
+		}
+		x[row] = v / a[row][row]
+	}
+	return x, nil
+}
+
+// validPairs returns the pairs of x and y without a NaN value.
+func validPairs(x, y []float64) (xs, ys []float64) {
+	for i := range min(len(x), len(y)) {
+		if !math.IsNaN(x[i]) && !math.IsNaN(y[i]) {
+			xs = append(xs, x[i])
+			ys = append(ys, y[i])
+		}
+	}
+	return xs, ys
+}
+
+// RegressionPlot draws the values of ycol against the ones of xcol as a scatter, with the line fitted
+// by ordinary least squares. The legend of the line shows its equation and R².
+// It panics if a column does not exist.
+func (d *dataFrame) RegressionPlot(xcol, ycol string, options ...ChartOption) {
+	for _, name := range []string{xcol, ycol} {
+		if d.GetColumn(name) == nil {
+			panic(fmt.Sprintf("column not found: %s", name))
+		}
+	}
+	x, y := d.GetColumn(xcol).ToFloat64(), d.GetColumn(ycol).ToFloat64()
+	slope, intercept, r2 := LinearFit(x, y)
+
+	chartOPs := []ChartOption{XName(xcol), YName(ycol), ScatterXY(ycol, x, y)}
+	if !math.IsNaN(slope) {
+		valid := slices.DeleteFunc(slices.Clone(x), math.IsNaN)
+		low, high := slices.Min(valid), slices.Max(valid)
+		sign := "+"
+		if intercept < 0 {
+			sign = "-"
+		}
+		label := fmt.Sprintf("y = %.4gx %s %.4g (R² = %.3f)", slope, sign, math.Abs(intercept), r2)
+		chartOPs = append(chartOPs, LineXY(label, []float64{low, high}, []float64{slope*low + intercept, slope*high + intercept}))
+	}
+
+	// chartOPs goes first for auto labels
+	options = append(chartOPs, options...)
+	c, err := NewXYChart(options...)
+	if err != nil {
+		log.Printf("print plot failed: %v", err)
+		return
+	}
+	d.printChart(c, c.conf)
+}
@@ -0,0 +1,312 @@
+package df
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resample buckets the rows into intervals of the time column, and aggregates the other columns in each bucket.
+//
+// The time column can hold time.Time values, strings in RFC 3339 or "2006-01-02 15:04:05" or "2006-01-02" layout,
+// or numbers of unix seconds. Rows whose time can not be parsed are skipped.
+// The freq is a duration such as "15m" or "1h", and it also accepts days and weeks such as "1d" and "1w".
+// Buckets are aligned to the wall clock in the time zone of the first time, and the times in other time zones
+// are converted to it. A freq which divides a day splits each day from midnight, and days and weeks are calendar
+// days, so a day is 23 or 25 hours long on the day of a daylight saving time change. Weeks start on Monday.
+//
+// The agg maps a column name to one of "sum", "mean", "min", "max", "count", "first" and "last".
+// Columns which are not in the map are dropped. If agg is nil, every numeric column is averaged.
+//
+// The result has a row for every bucket from the first to the last one, including the empty buckets:
+// "sum" and "count" are zero, "mean", "min" and "max" are NaN, and "first" and "last" are null.
+// If there are more than a million buckets, such as for a tiny freq or an outlier time,
+// the result only has the buckets with rows.
+// It panics if the time column does not exist, or the freq or an aggregation is invalid.
+func (df *dataFrame) Resample(timeCol string, freq string, agg map[string]string) DataFrame {
+	timeSeries := df.GetColumn(timeCol)
+	if timeSeries == nil {
+		panic(fmt.Sprintf("column not found: %s", timeCol))
+	}
+	d, err := parseFreq(freq)
+	if err != nil {
+		panic(err)
+	}
+
+	// Pick the columns to aggregate
+	if agg == nil {
+		agg = map[string]string{}
+		for _, name := range df.order {
+			if name != timeCol && isNumeric(df.GetColumn(name)) {
+				agg[name] = "mean"
+			}
+		}
+	}
+	var names []string
+	for _, name := range df.order {
+		if fn, ok := agg[name]; ok && name != timeCol {
+			if _, ok := aggregators[fn]; !ok {
+				panic(fmt.Sprintf("unsupported aggregation: %s", fn))
+			}
+			names = append(names, name)
+		}
+	}
+
+	// Find the bucket of each row
+	starts := make([]time.Time, df.Rows())
+	valid := make([]bool, df.Rows())
+	var loc *time.Location
+	var first, last time.Time
+	for i, v := range timeSeries.Data() {
+		t, ok := toTime(v)
+		if !ok {
+			continue
+		}
+		if loc == nil {
+			loc = t.Location()
+			first, last = floorTime(t, d), floorTime(t, d)
+		}
+		start := floorTime(t.In(loc), d)
+		if start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+		starts[i], valid[i] = start, true
+	}
+	if loc == nil {
+		columns := []Series{&series{name: timeCol}}
+		for _, name := range names {
+			columns = append(columns, &series{name: name})
+		}
+		return NewDataFrame(columns...)
+	}
+
+	// The buckets from the first to the last one, or only the buckets with rows if there are too many
+	var buckets []time.Time
+	if last.Sub(first)/d < maxResampleBuckets {
+		for b := first; !b.After(last); b = nextTime(b, d) {
+			buckets = append(buckets, b)
+		}
+	} else {
+		for i, start := range starts {
+			if valid[i] {
+				buckets = append(buckets, start)
+			}
+		}
+		slices.SortFunc(buckets, time.Time.Compare)
+		buckets = slices.CompactFunc(buckets, time.Time.Equal)
+	}
+
+	// Group the row indexes by bucket
+	index := make(map[int64]int, len(buckets))
+	times := make([]any, len(buckets))
+	for b, start := range buckets {
+		index[start.UnixNano()] = b
+		times[b] = start
+	}
+	groups := make([][]int, len(buckets))
+	for i, start := range starts {
+		if valid[i] {
+			b := index[start.UnixNano()]
+			groups[b] = append(groups[b], i)
+		}
+	}
+
+	// Aggregate each column
+	columns := []Series{&series{name: timeCol, data: times}}
+	for _, name := range names {
+		data := df.GetColumn(name).Data()
+		fn := aggregators[agg[name]]
+		values := make([]any, len(groups))
+		for b, rows := range groups {
+			cells := make([]any, len(rows))
+			for k, i := range rows {
+				cells[k] = data[i]
+			}
+			values[b] = fn(cells)
+		}
+		columns = append(columns, &series{name: name, data: values})
+	}
+	return NewDataFrame(columns...)
+}
+
+// maxResampleBuckets is the largest number of buckets of Resample, including the empty ones.
+const maxResampleBuckets = 1_000_000
+
+// aggregators reduce the values of a bucket, null and non-numeric values are ignored by the numeric ones.
+var aggregators = map[string]func(cells []any) any{
+	"sum": func(cells []any) any {
+		var sum float64
+		for _, v := range numbers(cells) {
+			sum += v
+		}
+		return sum
+	},
+	"mean": func(cells []any) any {
+		nums := numbers(cells)
+		if len(nums) == 0 {
+			return math.NaN()
+		}
+		return Avg(nums)
+	},
+	"min": func(cells []any) any {
+		nums := numbers(cells)
+		if len(nums) == 0 {
+			return math.NaN()
+		}
+		return slices.Min(nums)
+	},
+	"max": func(cells []any) any {
+		nums := numbers(cells)
+		if len(nums) == 0 {
+			return math.NaN()
+		}
+		return slices.Max(nums)
+	},
+	"count": func(cells []any) any {
+		count := 0
+		for _, v := range cells {
+			if v != nil {
+				count++
+			}
+		}
+		return count
+	},
+	"first": func(cells []any) any {
+		for _, v := range cells {
+			if v != nil {
+				return v
+			}
+		}
+		return nil
+	},
+	"last": func(cells []any) any {
+		for i := len(cells) - 1; i >= 0; i-- {
+			if cells[i] != nil {
+				return cells[i]
+			}
+		}
+		return nil
+	},
+}
+
+// numbers returns the numeric values of the cells as float64.
+func numbers(cells []any) []float64 {
+	var nums []float64
+	for _, v := range cells {
+		switch v := v.(type) {
+		case float64:
+			nums = append(nums, v)
+		case int:
+			nums = append(nums, float64(v))
+		}
+	}
+	return nums
+}
+
+// parseFreq parses a duration, with the extra units "d" for days and "w" for weeks.
+func parseFreq(freq string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(freq, "d"), strings.HasSuffix(freq, "w"):
+		unit := 24 * time.Hour
+		if strings.HasSuffix(freq, "w") {
+			unit *= 7
+		}
+		var n int
+		n, err = strconv.Atoi(freq[:len(freq)-1])
+		d = time.Duration(n) * unit
+	default:
+		d, err = time.ParseDuration(freq)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid frequency: %q", freq)
+	}
+	return d, nil
+}
+
+// timeLayouts are the layouts to parse a time string.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// toTime converts a time.Time, a time string or unix seconds to a time.
+func toTime(v any) (time.Time, bool) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	case int:
+		return time.Unix(int64(v), 0).UTC(), true
+	case float64:
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			sec, frac := math.Modf(v)
+			return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// floorTime returns the start of the bucket of length d which contains t, aligned to the wall clock in the time
+// zone of t. A d which divides a day splits each day from midnight, a multiple of a day is a number of calendar
+// days counted from January 1 of year 1, which is a Monday, and another d is aligned to the zero time.
+func floorTime(t time.Time, d time.Duration) time.Time {
+	const day = 24 * time.Hour
+	year, month, dd := t.Date()
+	switch {
+	case day%d == 0:
+		clock := timeOfDay(t)
+		floor := clock.Truncate(d)
+		// Going back keeps the offset of t, so the hours which are repeated when the clocks go back are
+		// separate buckets, unless the clocks change in between
+		if start := t.Add(floor - clock); start.Day() == dd && timeOfDay(start) == floor {
+			return start
+		}
+		start := time.Date(year, month, dd, 0, 0, 0, int(floor), t.Location())
+		if timeOfDay(start) != floor {
+			// The clocks go forward from the skipped start to the start of the zone of t
+			start, _ = t.ZoneBounds()
+		}
+		return start
+	case d%day == 0:
+		days := int64(d / day)
+		n := time.Date(year, month, dd, 0, 0, 0, 0, time.UTC).Unix()/86400 - zeroDay
+		return time.Date(year, month, dd-int((n%days+days)%days), 0, 0, 0, 0, t.Location())
+	}
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(d).Add(-shift)
+}
+
+// timeOfDay returns the wall clock time of t since midnight.
+func timeOfDay(t time.Time) time.Duration {
+	hour, minute, second := t.Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute +
+		time.Duration(second)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// zeroDay is the day of January 1 of year 1, counted in days since the Unix epoch.
+var zeroDay = time.Time{}.Unix() / 86400
+
+// nextTime returns the start of the bucket after the one which starts at t, see floorTime.
+func nextTime(t time.Time, d time.Duration) time.Time {
+	if d%(24*time.Hour) == 0 {
+		year, month, dd := t.Date()
+		return time.Date(year, month, dd+int(d/(24*time.Hour)), 0, 0, 0, 0, t.Location())
+	}
+	// A bucket is longer than d if the clocks go back in it
+	for next := t.Add(d); ; next = next.Add(d) {
+		if start := floorTime(next, d); start.After(t) {
+			return start
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package df
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Quantile returns the q-th quantile of data, with linear interpolation between the closest values.
+// q must be in [0, 1], so 0 is the minimum, 0.5 is the median and 1 is the maximum.
+// NaN values are ignored, and it returns NaN if there is no value.
+func Quantile(data []float64, q float64) float64 {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		panic(fmt.Sprintf("quantile out of range: %v", q))
+	}
+	sorted := slices.DeleteFunc(slices.Clone(data), math.IsNaN)
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	slices.Sort(sorted)
+
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i == len(sorted)-1 {
+		return sorted[i]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// Quantile returns a series with the q-th quantile of the values, null values are ignored.
+// The quantile of a time series is a time.
+func (s *series) Quantile(q float64) Series {
+	v := Quantile(s.numbers(), q)
+	if t, ok := firstValue(s.data).(time.Time); ok {
+		return NewTimeSeries(s.name, []time.Time{time.Unix(0, int64(v*1e9)).In(t.Location())})
+	}
+	return NewSeries(s.name, []float64{v})
+}
+
+// numbers converts the values for the statistics, it panics for a string series.
+// The values of an all null series are NaN.
+func (s *series) numbers() []float64 {
+	switch firstValue(s.data).(type) {
+	case string:
+		panic("unsupported")
+	case nil:
+		return Map(s.data, func(any) float64 { return math.NaN() })
+	}
+	return s.ToFloat64()
+}
+
+// Var returns the variance of data. If sample is true, it is the sample variance which divides by n-1,
+// otherwise it is the population variance which divides by n.
+// It returns NaN if there are not enough values.
+func Var[T float64 | int](data []T, sample bool) float64 {
+	n := len(data)
+	if sample {
+		n--
+	}
+	if n <= 0 {
+		return math.NaN()
+	}
+
+	avg := Avg(data)
+	var sum float64
+	for _, v := range data {
+		d := float64(v) - avg
+		sum += d * d
+	}
+	return sum / float64(n)
+}
+
+// Std returns the standard deviation of data, which is the square root of Var.
+func Std[T float64 | int](data []T, sample bool) float64 {
+	return math.Sqrt(Var(data, sample))
+}
+
+// Var returns a series with the sample variance of the values, null values are ignored.
+func (s *series) Var() Series {
+	return NewSeries(s.name, []float64{Var(s.values(), true)})
+}
+
+// Std returns a series with the sample standard deviation of the values, null values are ignored.
+// The standard deviation of a time series is in seconds.
+func (s *series) Std() Series {
+	return NewSeries(s.name, []float64{Std(s.values(), true)})
+}
+
+// values returns the non-null values of numbers.
+func (s *series) values() []float64 {
+	return slices.DeleteFunc(s.numbers(), math.IsNaN)
+}
+
+// Cumsum returns a series of the running sums of the values, which has the same type as s.
+// A null or NaN value results in null, and it is skipped by the following sums.
+// It panics if the series is not an int or float64 series.
+func (s *series) Cumsum() Series {
+	return s.cumulate(func(a, b float64) float64 { return a + b }, 0)
+}
+
+// Cumprod returns a series of the running products of the values, like Cumsum.
+func (s *series) Cumprod() Series {
+	return s.cumulate(func(a, b float64) float64 { return a * b }, 1)
+}
+
+func (s *series) cumulate(op func(a, b float64) float64, init float64) Series {
+	var isInt bool
+	switch firstValue(s.data).(type) {
+	case int:
+		isInt = true
+	case float64, nil:
+	default:
+		panic("unsupported")
+	}
+
+	acc := init
+	data := make([]any, len(s.data))
+	for i, v := range s.data {
+		switch v := v.(type) {
+		case nil:
+			continue
+		case int:
+			acc = op(acc, float64(v))
+		case float64:
+			if math.IsNaN(v) {
+				continue
+			}
+			acc = op(acc, v)
+		}
+		if isInt {
+			data[i] = int(acc)
+		} else {
+			data[i] = acc
+		}
+	}
+	return &series{name: s.name, data: data}
+}
+
+// MinMaxScale returns a float64 series of the values scaled to [0, 1], null values stay null.
+// A constant series results in zeros.
+func (s *series) MinMaxScale() Series {
+	values := s.values()
+	if len(values) == 0 {
+		return s.scale(0, 1)
+	}
+	low, high := slices.Min(values), slices.Max(values)
+	return s.scale(low, high-low)
+}
+
+// ZScore returns a float64 series of the standardized values, which are the numbers of population
+// standard deviations from the mean. Null values stay null, and a constant series results in zeros.
+func (s *series) ZScore() Series {
+	values := s.values()
+	return s.scale(Avg(values), Std(values, false))
+}
+
+// scale returns a series of (v - offset) / unit, or zeros if the unit is zero.
+func (s *series) scale(offset, unit float64) Series {
+	data := make([]any, len(s.data))
+	for i, v := range s.numbers() {
+		switch {
+		case math.IsNaN(v):
+		case unit == 0:
+			data[i] = 0.0
+		default:
+			data[i] = (v - offset) / unit
+		}
+	}
+	return &series{name: s.name, data: data}
+}
+
+// RollingMean returns a float64 series of the means of the values in a moving window, which ends at
+// each position. The first window-1 positions are null, and so is a window with only null values.
+// It panics if the window is not positive.
+func (s *series) RollingMean(window int) Series {
+	if window <= 0 {
+		panic(fmt.Sprintf("invalid window: %d", window))
+	}
+	values := s.numbers()
+	data := make([]any, len(values))
+	for i := window - 1; i < len(values); i++ {
+		var sum float64
+		var n int
+		for _, v := range values[i-window+1 : i+1] {
+			if !math.IsNaN(v) {
+				sum += v
+				n++
+			}
+		}
+		if n > 0 {
+			data[i] = sum / float64(n)
+		}
+	}
+	return &series{name: s.name, data: data}
+}
@@ -0,0 +1,106 @@
+package df
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	nan := math.NaN()
+	for _, tc := range []struct {
+		data []float64
+		q    float64
+		want float64
+	}{
+		{[]float64{3, 1, 4, 2}, 0, 1},
+		{[]float64{3, 1, 4, 2}, 1, 4},
+		{[]float64{3, 1, 4, 2}, 0.5, 2.5},
+		{[]float64{3, 1, 4, 2}, 0.25, 1.75},
+		{[]float64{3, 1, 4, 2}, 0.99, 3.97},
+		{[]float64{5}, 0, 5},
+		{[]float64{5}, 1, 5},
+		// NaN values are ignored
+		{[]float64{nan, 10, nan, 20}, 0.5, 15},
+		{[]float64{nan, 10, nan, 20}, 1, 20},
+		{nil, 0.5, nan},
+		{[]float64{nan, nan}, 0.5, nan},
+	} {
+		got := Quantile(tc.data, tc.q)
+		if math.Abs(got-tc.want) > 1e-9 && !(math.IsNaN(got) && math.IsNaN(tc.want)) {
+			t.Errorf("Quantile(%v, %v) = %v, want %v", tc.data, tc.q, got, tc.want)
+		}
+	}
+
+	// The quantile of a series ignores null values
+	s := NewSeriesAny("a", []any{nil, 1, 3, nil})
+	if got := s.Quantile(0.5).Data()[0]; got != 2.0 {
+		t.Errorf("got %v, want 2", got)
+	}
+	if got := NewSeriesAny("a", []any{nil}).Quantile(0.5).Data()[0].(float64); !math.IsNaN(got) {
+		t.Errorf("got %v, want NaN", got)
+	}
+
+	for _, q := range []float64{-0.1, 1.1, nan} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Quantile with q %v did not panic", q)
+				}
+			}()
+			Quantile([]float64{1}, q)
+		}()
+	}
+}
+
+func TestVar(t *testing.T) {
+	nan := math.NaN()
+	data := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	for _, tc := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		// The sample variance divides by n-1, and the population variance by n
+		{"sample", Var(data, true), 32.0 / 7},
+		{"population", Var(data, false), 4},
+		{"std", Std(data, false), 2},
+		{"ints", Var([]int{1, 2, 3, 4}, true), 5.0 / 3},
+		{"single sample", Var([]float64{3}, true), nan},
+		{"single population", Var([]float64{3}, false), 0},
+		{"empty", Var([]float64{}, false), nan},
+		// A NaN value makes the result NaN, the series methods ignore it
+		{"NaN", Var([]float64{1, nan, 3}, true), nan},
+		{"series", NewSeriesAny("a", []any{1.0, nan, nil, 3.0}).Var().Data()[0].(float64), 2},
+		{"series std", NewSeries("a", []int{1, 3}).Std().Data()[0].(float64), math.Sqrt2},
+		{"series single", NewSeriesAny("a", []any{nil, 1.0}).Std().Data()[0].(float64), nan},
+		{"series null", NewSeriesAny("a", []any{nil, nil}).Var().Data()[0].(float64), nan},
+	} {
+		if math.Abs(tc.got-tc.want) > 1e-9 && !(math.IsNaN(tc.got) && math.IsNaN(tc.want)) {
+			t.Errorf("%s: got %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	nan := math.NaN()
+	for _, tc := range []struct {
+		name string
+		got  Series
+		want []any
+	}{
+		{"MinMaxScale", NewSeries("a", []int{2, 4, 6}).MinMaxScale(), []any{0.0, 0.5, 1.0}},
+		{"ZScore", NewSeries("a", []float64{1, 3}).ZScore(), []any{-1.0, 1.0}},
+		// A constant series is zeros, and null and NaN values are null
+		{"MinMaxScale constant", NewSeriesAny("a", []any{5.0, nil, 5.0, nan}).MinMaxScale(), []any{0.0, nil, 0.0, nil}},
+		{"ZScore constant", NewSeriesAny("a", []any{5, nil, 5}).ZScore(), []any{0.0, nil, 0.0}},
+		{"ZScore single", NewSeries("a", []float64{7}).ZScore(), []any{0.0}},
+		{"MinMaxScale NaN", NewSeriesAny("a", []any{nan, 1.0, 3.0}).MinMaxScale(), []any{nil, 0.0, 1.0}},
+		{"ZScore null", NewSeriesAny("a", []any{nil, nil}).ZScore(), []any{nil, nil}},
+		{"MinMaxScale null", NewSeriesAny("a", []any{nil}).MinMaxScale(), []any{nil}},
+	} {
+		if got := tc.got.Data(); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
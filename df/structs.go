@@ -0,0 +1,112 @@
+package df
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// FromStructs creates a DataFrame from a slice of structs, or pointers to structs, with a column for each exported field
+// in the order of the fields. The name of a column is the name of its field, or the name in a `df:"name"` tag,
+// and a field with a `df:"-"` tag is left out. The fields of an embedded struct are columns of their own.
+//
+// The integer fields are int columns, the floating-point fields are float64 columns, and the fields of the other
+// types of a Series keep their types. A nil pointer field, or a nil row, is a null value.
+// It returns an error if T is not a struct, a field has another type, or an integer doesn't fit in an int,
+// such as a uint64 above math.MaxInt64.
+func FromStructs[T any](rows []T) (DataFrame, error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("not a struct: %s", typ)
+	}
+
+	var fields []reflect.StructField
+	var names []string
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous && field.Type != timeType {
+			continue
+		}
+		name, ok := field.Tag.Lookup("df")
+		if name == "-" {
+			continue
+		}
+		if !ok || name == "" {
+			name = field.Name
+		}
+		if _, err := cellValue(reflect.Zero(field.Type)); err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		fields = append(fields, field)
+		names = append(names, name)
+	}
+
+	columns := make([][]any, len(fields))
+	for _, row := range rows {
+		v := reflect.ValueOf(&row).Elem()
+		if v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		for i, field := range fields {
+			var cell any
+			if v.IsValid() {
+				// The field is not reachable through a nil embedded pointer
+				if f, err := v.FieldByIndexErr(field.Index); err == nil {
+					if cell, err = cellValue(f); err != nil {
+						return nil, fmt.Errorf("field %s: %w", field.Name, err)
+					}
+				}
+			}
+			columns[i] = append(columns[i], cell)
+		}
+	}
+
+	df := NewDataFrame()
+	for i, name := range names {
+		if err := df.SetColumn(NewSeriesAny(name, columns[i])); err != nil {
+			return nil, err
+		}
+	}
+	return df, nil
+}
+
+// cellValue returns the value of a struct field as a cell of a Series, or nil for a nil pointer.
+func cellValue(v reflect.Value) (any, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			// The zero value checks the type of the element
+			if _, err := cellValue(reflect.Zero(v.Type().Elem())); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time), nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := v.Int(); int64(int(i)) == i {
+			return int(i), nil
+		}
+		return nil, fmt.Errorf("int overflow: %d", v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u <= math.MaxInt {
+			return int(u), nil
+		}
+		return nil, fmt.Errorf("int overflow: %d", v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	}
+	return nil, fmt.Errorf("unsupported type: %s", v.Type())
+}
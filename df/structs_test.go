@@ -0,0 +1,80 @@
+package df
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromStructs(t *testing.T) {
+	type Base struct {
+		ID uint8
+	}
+	type row struct {
+		Base
+		Name   string `df:"name"`
+		Score  *float32
+		Big    uint64
+		Small  int16
+		When   time.Time
+		Hidden string `df:"-"`
+		secret int
+	}
+	score := float32(1.5)
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d, err := FromStructs([]*row{
+		{Base{1}, "a", &score, math.MaxInt64, -3, day, "x", 0},
+		nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := d.Columns(), []string{"ID", "name", "Score", "Big", "Small", "When"}; !slices.Equal(got, want) {
+		t.Fatalf("got columns %v, want %v", got, want)
+	}
+	for _, tc := range []struct {
+		name string
+		want []any
+	}{
+		{"ID", []any{1, nil}},
+		{"name", []any{"a", nil}},
+		{"Score", []any{1.5, nil}},
+		{"Big", []any{math.MaxInt64, nil}},
+		{"Small", []any{-3, nil}},
+		{"When", []any{day, nil}},
+	} {
+		if got := d.GetColumn(tc.name).Data(); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFromStructsErrors(t *testing.T) {
+	// An unsigned integer above math.MaxInt64 doesn't wrap to a negative int
+	type big struct {
+		N uint64
+	}
+	_, err := FromStructs([]big{{1}, {math.MaxUint64}})
+	if err == nil || !strings.Contains(err.Error(), "field N: int overflow: 18446744073709551615") {
+		t.Errorf("got error %v, want an overflow", err)
+	}
+	type ptr struct {
+		N *uintptr
+	}
+	n := uintptr(math.MaxInt64 + 1)
+	if _, err := FromStructs([]ptr{{&n}}); err == nil || !strings.Contains(err.Error(), "int overflow") {
+		t.Errorf("got error %v, want an overflow", err)
+	}
+
+	type unsupported struct {
+		C complex128
+	}
+	if _, err := FromStructs([]unsupported{{}}); err == nil || !strings.Contains(err.Error(), "field C") {
+		t.Errorf("got error %v, want an unsupported field", err)
+	}
+	if _, err := FromStructs([]int{1}); err == nil || !strings.Contains(err.Error(), "not a struct") {
+		t.Errorf("got error %v, want not a struct", err)
+	}
+}
@@ -0,0 +1,167 @@
+package df
+
+import "sync"
+
+// SyncDataFrame is a DataFrame which can be used by multiple goroutines at the same time, such as a frame
+// which is updated by one goroutine and drawn by another one for a live dashboard.
+//
+// All methods are safe for concurrent use. The changes of the columns, by SetColumn, SetColumnAt, RemoveColumn
+// and RemoveColumnAt, are exclusive, while the other methods read the DataFrame together. The charts and
+// the HTML are made from a copy, so that a slow chart doesn't hold up the changes. Use Update for several
+// changes which should be seen together.
+//
+// A Series returned by GetColumn is shared with the DataFrame, so its data must not be changed in place.
+// Replace the column with SetColumn instead.
+type SyncDataFrame struct {
+	mu sync.RWMutex
+	d  DataFrame
+}
+
+var _ DataFrame = (*SyncDataFrame)(nil)
+
+// NewSyncDataFrame wraps a DataFrame for concurrent use. The DataFrame must not be used directly afterwards.
+func NewSyncDataFrame(d DataFrame) *SyncDataFrame {
+	return &SyncDataFrame{d: d}
+}
+
+// Update calls fn with the DataFrame, which no other goroutine can use until fn returns.
+// The DataFrame must not be kept after fn returns.
+func (s *SyncDataFrame) Update(fn func(d DataFrame)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.d)
+}
+
+func (s *SyncDataFrame) String() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.String()
+}
+
+func (s *SyncDataFrame) Columns() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.Columns()
+}
+
+func (s *SyncDataFrame) Rows() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.Rows()
+}
+
+func (s *SyncDataFrame) GetColumn(name string) Series {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.GetColumn(name)
+}
+
+func (s *SyncDataFrame) GetColumnAt(index int) Series {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.GetColumnAt(index)
+}
+
+func (s *SyncDataFrame) SetColumn(data Series) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.d.SetColumn(data)
+}
+
+func (s *SyncDataFrame) SetColumnAt(index int, data Series) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.d.SetColumnAt(index, data)
+}
+
+func (s *SyncDataFrame) RemoveColumn(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.d.RemoveColumn(name)
+}
+
+func (s *SyncDataFrame) RemoveColumnAt(index int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.d.RemoveColumnAt(index)
+}
+
+// Copy returns a copy of the DataFrame, which is not synchronized.
+func (s *SyncDataFrame) Copy() DataFrame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.Copy()
+}
+
+func (s *SyncDataFrame) Head(n int) DataFrame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.Head(n)
+}
+
+func (s *SyncDataFrame) Tail(n int) DataFrame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.Tail(n)
+}
+
+func (s *SyncDataFrame) Avg() DataFrame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.Avg()
+}
+
+func (s *SyncDataFrame) Resample(timeCol string, freq string, agg map[string]string) DataFrame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.Resample(timeCol, freq, agg)
+}
+
+func (s *SyncDataFrame) FilterMask(mask Series) DataFrame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.FilterMask(mask)
+}
+
+func (s *SyncDataFrame) Shift(n int, columns ...string) DataFrame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.d.Shift(n, columns...)
+}
+
+// Merge joins a copy of the DataFrame with the other one, which may be the same SyncDataFrame.
+func (s *SyncDataFrame) Merge(other DataFrame, opts MergeOptions) DataFrame {
+	return s.Copy().Merge(other, opts)
+}
+
+func (s *SyncDataFrame) HTML() string {
+	return s.Copy().HTML()
+}
+
+func (s *SyncDataFrame) Plot(options ...ChartOption) {
+	s.Copy().Plot(options...)
+}
+
+func (s *SyncDataFrame) Bar(options ...ChartOption) {
+	s.Copy().Bar(options...)
+}
+
+func (s *SyncDataFrame) Line(options ...ChartOption) {
+	s.Copy().Line(options...)
+}
+
+func (s *SyncDataFrame) Pie(options ...ChartOption) {
+	s.Copy().Pie(options...)
+}
+
+func (s *SyncDataFrame) Combo(kinds map[string]string, options ...ChartOption) {
+	s.Copy().Combo(kinds, options...)
+}
+
+func (s *SyncDataFrame) XY(options ...ChartOption) {
+	s.Copy().XY(options...)
+}
+
+func (s *SyncDataFrame) RegressionPlot(xcol, ycol string, options ...ChartOption) {
+	s.Copy().RegressionPlot(xcol, ycol, options...)
+}
@@ -0,0 +1,333 @@
+package df
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/discoverkl/goterm/term"
+)
+
+// TableStyle is the default style of the HTML table rendered from a DataFrame.
+const TableStyle = `
+table.df-table {
+	border-collapse: collapse;
+	font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif;
+	font-size: 0.875rem;
+	color: #333;
+}
+table.df-table th, table.df-table td {
+	padding: 4px 12px;
+	border-bottom: 1px solid #e5e5e5;
+	white-space: nowrap;
+}
+table.df-table th {
+	text-align: right;
+	font-weight: 600;
+	border-bottom: 2px solid #ccc;
+}
+table.df-table td {
+	text-align: right;
+	font-variant-numeric: tabular-nums;
+}
+table.df-table td.df-text {
+	text-align: left;
+}
+table.df-table tbody tr:nth-child(even) {
+	background-color: #f6f8fa;
+}
+table.df-table tbody tr:hover {
+	background-color: #eef3fb;
+}
+div.df-pager {
+	display: flex;
+	align-items: center;
+	gap: 8px;
+	margin-top: 8px;
+	font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif;
+	font-size: 0.875rem;
+	color: #333;
+}
+table.df-table tfoot td {
+	font-weight: 600;
+	border-top: 2px solid #ccc;
+	border-bottom: none;
+}
+`
+
+// pagerScript shows a page of the rows of a paginated table, which are kept in a JSON script element,
+// so that only one page is in the document. The format argument is the page size.
+const pagerScript = `<script>
+(function() {
+    const root = document.currentScript && document.currentScript.parentElement;
+    if (!root) {
+        return;
+    }
+    const rows = JSON.parse(root.querySelector('script.df-rows').textContent);
+    const size = %d;
+    const pages = Math.ceil(rows.length / size);
+    const tbody = root.querySelector('tbody');
+    const label = root.querySelector('.df-page');
+    const prev = root.querySelector('.df-prev');
+    const next = root.querySelector('.df-next');
+    let page = 0;
+
+    function show(p) {
+        page = Math.min(Math.max(p, 0), pages - 1);
+        tbody.innerHTML = rows.slice(page * size, (page + 1) * size).join('');
+        label.textContent = 'Page ' + (page + 1) + ' of ' + pages;
+        prev.disabled = page === 0;
+        next.disabled = page === pages - 1;
+    }
+    prev.addEventListener('click', function() { show(page - 1); });
+    next.addEventListener('click', function() { show(page + 1); });
+})();
+</script>`
+
+// DefaultPageSize is the number of rows in a page of a table, see the PageSize option.
+const DefaultPageSize = 50
+
+// Table renders a DataFrame as an HTML table. It implements the term.BlockWithOption interface.
+type Table struct {
+	d    DataFrame
+	conf *tableConfig
+}
+
+type tableConfig struct {
+	width    int
+	height   int
+	maxRows  int
+	heatmap  []string
+	footers  []string // "sum" or "mean"
+	pageSize int
+}
+
+type TableOption func(*tableConfig)
+
+// TableSize sets the size of the table block. The table will scroll if it is larger than the given size.
+func TableSize(width, height int) TableOption {
+	return func(c *tableConfig) {
+		c.width = width
+		c.height = height
+	}
+}
+
+// MaxRows shows only the first n rows of the table, followed by the number of the rows left out.
+func MaxRows(n int) TableOption {
+	return func(c *tableConfig) {
+		c.maxRows = n
+	}
+}
+
+// Heatmap shades the cells of the numeric column from green for the minimum to red for the maximum,
+// like the conditional formatting of a spreadsheet. Null cells are not shaded.
+func Heatmap(column string) TableOption {
+	return func(c *tableConfig) {
+		c.heatmap = append(c.heatmap, column)
+	}
+}
+
+// PageSize splits the rows of a table into pages of n rows, with buttons to go to the previous and
+// the next page. Only the rows of one page are in the document, so a large table doesn't slow down the browser.
+// The default is DefaultPageSize, and 0 shows all rows at once.
+func PageSize(n int) TableOption {
+	return func(c *tableConfig) {
+		c.pageSize = n
+	}
+}
+
+// WithTotals adds a footer row with the sum of each numeric column, the cells of the other columns are blank.
+func WithTotals() TableOption {
+	return func(c *tableConfig) {
+		c.footers = append(c.footers, "sum")
+	}
+}
+
+// WithMeans adds a footer row with the mean of each numeric column, the cells of the other columns are blank.
+func WithMeans() TableOption {
+	return func(c *tableConfig) {
+		c.footers = append(c.footers, "mean")
+	}
+}
+
+// PrintTable prints the DataFrame as aligned columns with a separator line under the header,
+// so that it's shown as text in the terminal. It supports the MaxRows option.
+func PrintTable(d DataFrame, options ...TableOption) {
+	c := &tableConfig{}
+	for _, option := range options {
+		option(c)
+	}
+	// A copy doesn't change while it's formatted, even if the DataFrame is changed by another goroutine
+	fmt.Println(tableText(d.Copy(), true, c.maxRows))
+}
+
+// HTMLTable creates a BlockElement which displays the given DataFrame as a styled HTML table.
+func HTMLTable(d DataFrame, options ...TableOption) *Table {
+	c := &tableConfig{pageSize: DefaultPageSize}
+	for _, option := range options {
+		option(c)
+	}
+	return &Table{d: d, conf: c}
+}
+
+func (t *Table) HTML() string {
+	d := t.d
+	var buf bytes.Buffer
+
+	buf.WriteString(`<div class="df-table" style="overflow: auto">`)
+	buf.WriteString("<style>")
+	buf.WriteString(TableStyle)
+	buf.WriteString("</style>")
+	buf.WriteString(`<table class="df-table">`)
+
+	// header
+	buf.WriteString("<thead><tr>")
+	for _, col := range d.Columns() {
+		fmt.Fprintf(&buf, "<th>%s</th>", html.EscapeString(col))
+	}
+	buf.WriteString("</tr></thead>")
+
+	// body, which shows the first page if the rows are paginated
+	rows := t.rows()
+	paged := t.conf.pageSize > 0 && len(rows) > t.conf.pageSize
+	buf.WriteString("<tbody>")
+	if paged {
+		buf.WriteString(strings.Join(rows[:t.conf.pageSize], ""))
+	} else {
+		buf.WriteString(strings.Join(rows, ""))
+	}
+	buf.WriteString("</tbody>")
+
+	// footer
+	if len(t.conf.footers) > 0 {
+		buf.WriteString("<tfoot>")
+		for _, footer := range t.conf.footers {
+			buf.WriteString("<tr>")
+			for _, col := range d.Columns() {
+				fmt.Fprintf(&buf, "<td>%s</td>", html.EscapeString(footerCell(d.GetColumn(col), footer)))
+			}
+			buf.WriteString("</tr>")
+		}
+		buf.WriteString("</tfoot>")
+	}
+
+	buf.WriteString("</table>")
+	if paged {
+		pages := (len(rows) + t.conf.pageSize - 1) / t.conf.pageSize
+		data, _ := json.Marshal(rows)
+		buf.WriteString(`<div class="df-pager"><button class="df-prev" disabled>Prev</button>`)
+		fmt.Fprintf(&buf, `<span class="df-page">Page 1 of %d</span>`, pages)
+		buf.WriteString(`<button class="df-next">Next</button></div>`)
+		fmt.Fprintf(&buf, `<script type="application/json" class="df-rows">%s</script>`, data)
+		fmt.Fprintf(&buf, pagerScript, t.conf.pageSize)
+	}
+	buf.WriteString("</div>")
+	return buf.String()
+}
+
+// rows returns the HTML of the rows of the table body.
+func (t *Table) rows() []string {
+	d := t.d
+	if d.Rows() == 0 {
+		return nil
+	}
+
+	columns := make([]Series, len(d.Columns()))
+	formats := make([]string, len(columns))
+	for j := range columns {
+		columns[j] = d.GetColumnAt(j)
+		formats[j] = seriesFormat(columns[j])
+	}
+	shades := t.shades()
+	n := d.Rows()
+	if t.conf.maxRows > 0 {
+		n = min(n, t.conf.maxRows)
+	}
+
+	var rows []string
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		buf.Reset()
+		buf.WriteString("<tr>")
+		for j, s := range columns {
+			attrs := ""
+			if formats[j] == "%s" {
+				attrs = ` class="df-text"`
+			}
+			if shade, ok := shades[s.Name()]; ok && shade[i] != nil {
+				// The hue goes from 120 (green) to 0 (red)
+				attrs += fmt.Sprintf(` style="background-color: hsl(%.0f, 70%%, 80%%)"`, 120*(1-shade[i].(float64)))
+			}
+			cell := formatCell(formats[j], s.Data()[i])
+			fmt.Fprintf(&buf, "<td%s>%s</td>", attrs, html.EscapeString(cell))
+		}
+		buf.WriteString("</tr>")
+		rows = append(rows, buf.String())
+	}
+	if n < d.Rows() {
+		rows = append(rows, fmt.Sprintf(`<tr><td class="df-text" colspan="%d">... %d more rows</td></tr>`, len(columns), d.Rows()-n))
+	}
+	return rows
+}
+
+// footerCell returns the sum or the mean of the values of a numeric series, null values are ignored.
+// It returns an empty string for the other series.
+func footerCell(s Series, footer string) string {
+	switch firstValue(s.Data()).(type) {
+	case int, float64:
+	default:
+		return ""
+	}
+	values := slices.DeleteFunc(s.ToFloat64(), math.IsNaN)
+
+	var v any
+	switch footer {
+	case "sum":
+		var sum float64
+		for _, x := range values {
+			sum += x
+		}
+		v = sum
+		if _, ok := firstValue(s.Data()).(int); ok {
+			v = int(sum)
+		}
+	case "mean":
+		v = Avg(values)
+	}
+	return formatCell(cellFormat(v), v)
+}
+
+// shades returns the values of the Heatmap columns scaled to [0, 1], by the column names.
+// It panics if a column does not exist or is not numeric.
+func (t *Table) shades() map[string][]any {
+	shades := map[string][]any{}
+	for _, name := range t.conf.heatmap {
+		s := t.d.GetColumn(name)
+		if s == nil {
+			panic(fmt.Sprintf("column not found: %s", name))
+		}
+		switch firstValue(s.Data()).(type) {
+		case int, float64:
+		default:
+			panic(fmt.Sprintf("column is not numeric: %s", name))
+		}
+		shades[name] = s.MinMaxScale().Data()
+	}
+	return shades
+}
+
+func (t *Table) Options() []term.BlockOption {
+	if t.conf.width == 0 && t.conf.height == 0 {
+		return nil
+	}
+	return []term.BlockOption{term.SizeOption(t.conf.width, t.conf.height)}
+}
+
+// HTML renders the DataFrame as a table with the default options.
+func (df *dataFrame) HTML() string {
+	return HTMLTable(df).HTML()
+}
@@ -0,0 +1,107 @@
+package df
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHTMLTableEscape(t *testing.T) {
+	d := NewDataFrame(
+		NewSeries("<b>", []string{"a & b", "</script><script>alert(1)</script>"}),
+	)
+	got := HTMLTable(d, PageSize(1)).HTML()
+
+	for _, want := range []string{"<th>&lt;b&gt;</th>", `<td class="df-text">a &amp; b</td>`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q is not in the table", want)
+		}
+	}
+	// The rows of the pages are in a script element, which a cell must not close
+	if n := strings.Count(got, "</script>"); n != 2 {
+		t.Errorf("got %d closing script tags, want 2 of the rows and the pager", n)
+	}
+	if strings.Contains(got, "<script>alert") {
+		t.Error("a cell is not escaped")
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	d := NewDataFrame(
+		NewSeriesAny("a", []any{1, nil, 3, 2}),
+		NewSeries("b", []int{5, 5, 5, 5}),
+	)
+	got := HTMLTable(d, Heatmap("a"), Heatmap("b")).HTML()
+
+	// The minimum is green and the maximum is red. A null cell is not shaded, and a constant column is green
+	for _, want := range []string{
+		`<td style="background-color: hsl(120, 70%, 80%)">1</td>`,
+		`<td>null</td>`,
+		`<td style="background-color: hsl(0, 70%, 80%)">3</td>`,
+		`<td style="background-color: hsl(60, 70%, 80%)">2</td>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q is not in the table", want)
+		}
+	}
+	if n := strings.Count(got, `hsl(120, 70%, 80%)">5`); n != 4 {
+		t.Errorf("got %d green cells of the constant column, want 4", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a column which is not numeric")
+		}
+	}()
+	HTMLTable(NewDataFrame(NewSeries("s", []string{"x"})), Heatmap("s")).HTML()
+}
+
+func TestTableFooters(t *testing.T) {
+	d := NewDataFrame(
+		NewSeriesAny("a", []any{1, nil, 2}),
+		NewSeries("b", []float64{1, 2, math.NaN()}),
+		NewSeries("c", []string{"x", "y", "z"}),
+	)
+	got := HTMLTable(d, WithTotals(), WithMeans()).HTML()
+
+	// Null values are ignored, and the cells of a string column are blank
+	want := "<tfoot><tr><td>3</td><td>3.000000</td><td></td></tr><tr><td>1.500000</td><td>1.500000</td><td></td></tr></tfoot>"
+	if !strings.Contains(got, want) {
+		t.Errorf("got %q, want the footer %q", got, want)
+	}
+}
+
+func TestTablePages(t *testing.T) {
+	frame := func(n int) DataFrame {
+		return NewDataFrame(NewSeries("a", make([]int, n)))
+	}
+	tests := []struct {
+		rows    int
+		options []TableOption
+		pages   int // 0 if the table is not paginated
+		shown   int
+	}{
+		{DefaultPageSize, nil, 0, DefaultPageSize},
+		{DefaultPageSize + 1, nil, 2, DefaultPageSize},
+		{10, []TableOption{PageSize(5)}, 2, 5},
+		{11, []TableOption{PageSize(5)}, 3, 5},
+		{100, []TableOption{PageSize(0)}, 0, 100},
+		// The row of the rows left out by MaxRows is on the last page
+		{10, []TableOption{MaxRows(4), PageSize(4)}, 2, 4},
+		{0, []TableOption{PageSize(5)}, 0, 0},
+	}
+	for _, test := range tests {
+		got := HTMLTable(frame(test.rows), test.options...).HTML()
+		body, _, _ := strings.Cut(got, "</tbody>")
+		if n := strings.Count(body, "<tr>") - 1; n != test.shown {
+			t.Errorf("%d rows: got %d rows in the document, want %d", test.rows, n, test.shown)
+		}
+		if paged := strings.Contains(got, `class="df-pager"`); paged != (test.pages > 0) {
+			t.Errorf("%d rows: got pager %v, want %v", test.rows, paged, test.pages > 0)
+		}
+		if want := fmt.Sprintf("Page 1 of %d<", test.pages); test.pages > 0 && !strings.Contains(got, want) {
+			t.Errorf("%d rows: %q is not in the pager", test.rows, want)
+		}
+	}
+}
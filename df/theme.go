@@ -0,0 +1,52 @@
+package df
+
+import (
+	"image/color"
+	"sync"
+)
+
+// ChartTheme is the color theme of the charts.
+type ChartTheme string
+
+const (
+	LightTheme ChartTheme = "light" // Dark text and axes, for a light background
+	DarkTheme  ChartTheme = "dark"  // Light text and axes, for a dark background
+)
+
+var chartTheme = LightTheme
+
+// themeMu guards chartTheme and the colors of the gonum charts, which are read when a chart is created.
+var themeMu sync.RWMutex
+
+// SetChartTheme sets the color theme of the charts which are created after the call, both gonum and echarts charts.
+// The default is LightTheme. DarkTheme keeps the charts legible on a dark background, such as the terminal text.
+// A chart which is already created keeps its theme, even if it's printed after the call.
+// It's safe to call it while charts are created by other goroutines.
+func SetChartTheme(theme ChartTheme) {
+	var text, axis color.Color
+	switch theme {
+	case LightTheme:
+		text = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+		axis = color.RGBA{R: 0x6e, G: 0x70, B: 0x79, A: 0xff}
+	case DarkTheme:
+		text = color.RGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+		axis = color.RGBA{R: 0xb9, G: 0xbc, B: 0xc4, A: 0xff}
+	default:
+		panic("unsupported chart theme")
+	}
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	chartTheme = theme
+	textColor, legendTextColor = text, text
+	axisLineColor, axisTickColor, axisLabelColor = axis, axis, axis
+}
+
+// echartTheme returns the name of the echarts theme for the chart theme.
+func echartTheme() string {
+	themeMu.RLock()
+	defer themeMu.RUnlock()
+	if chartTheme == DarkTheme {
+		return "dark"
+	}
+	return "white"
+}
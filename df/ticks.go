@@ -0,0 +1,245 @@
+package df
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/types"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+	"gonum.org/v1/plot"
+)
+
+// defaultTickCount is the number of ticks when only the format of the ticks is set.
+const defaultTickCount = 5
+
+// niceTicks returns about n evenly spaced ticks which cover [low, high],
+// with a step of 1, 2, 2.5 or 5 times a power of 10 which is the closest to the exact step.
+func niceTicks(low, high float64, n int) []float64 {
+	if math.IsNaN(low) || math.IsNaN(high) || math.IsInf(low, 0) || math.IsInf(high, 0) {
+		return nil
+	}
+	if high <= low {
+		return []float64{low}
+	}
+	n = max(n, 2)
+
+	raw := (high - low) / float64(n-1)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := mag
+	for _, m := range []float64{2, 2.5, 5, 10} {
+		if math.Abs(m*mag-raw) < math.Abs(step-raw) {
+			step = m * mag
+		}
+	}
+
+	first, last := math.Floor(low/step), math.Ceil(high/step)
+	ticks := make([]float64, 0, int(last-first)+1)
+	for i := first; i <= last; i++ {
+		ticks = append(ticks, i*step)
+	}
+	return ticks
+}
+
+// countTicks is a plot.Ticker which places about n major ticks, see the TickCount option.
+type countTicks int
+
+func (n countTicks) Ticks(min, max float64) []plot.Tick {
+	var ticks []plot.Tick
+	for _, v := range niceTicks(min, max, int(n)) {
+		if v >= min && v <= max {
+			ticks = append(ticks, plot.Tick{Value: v, Label: fmt.Sprint(v)})
+		}
+	}
+	return ticks
+}
+
+// formatTicks is a plot.Ticker which formats the labels of the major ticks of another ticker.
+type formatTicks struct {
+	plot.Ticker
+	format func(float64) string
+}
+
+func (t formatTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := t.Ticker.Ticks(min, max)
+	for i := range ticks {
+		if ticks[i].Label != "" {
+			ticks[i].Label = t.format(ticks[i].Value)
+		}
+	}
+	return ticks
+}
+
+// xLabels returns the labels of the x axis of an echarts chart, which are formatted by the XTickFormat option if they are numbers.
+func (c *chartConfig) xLabels(s Series) []string {
+	ret := labels(s)
+	format := c.tickFormat(c.xTickFormat)
+	if format == nil {
+		return ret
+	}
+	for i, v := range s.Data() {
+		if f, ok := toNumber(v); ok && !isTime(s) {
+			ret[i] = format(f)
+		}
+	}
+	return ret
+}
+
+// tickFormat returns the format of the tick labels of an axis, which is the given format of a tick option,
+// or the format of the Locale option. It returns nil if there is neither.
+func (c *chartConfig) tickFormat(format func(float64) string) func(float64) string {
+	if format != nil || c.locale == "" {
+		return format
+	}
+	p := message.NewPrinter(language.Make(c.locale))
+	return func(v float64) string {
+		return p.Sprint(number.Decimal(v, number.MaxFractionDigits(6)))
+	}
+}
+
+// localeFormatter returns the function which formats a number by the Locale option in the browser.
+func (c *chartConfig) localeFormatter() types.FuncStr {
+	return opts.FuncOpts(fmt.Sprintf(
+		`function (value) { return typeof value === 'number' ? new Intl.NumberFormat('%s', {maximumFractionDigits: 6}).format(value) : value; }`,
+		language.Make(c.locale)))
+}
+
+// ticker returns the ticker of an axis of a gonum chart for the tick options, based on the given ticker.
+func (c *chartConfig) ticker(base plot.Ticker, format func(float64) string) plot.Ticker {
+	if c.tickCount > 0 {
+		// Time ticks keep their labels, and only the placement is changed
+		if t, ok := base.(plot.TimeTicks); ok {
+			t.Ticker = countTicks(c.tickCount)
+			base = t
+		} else {
+			base = countTicks(c.tickCount)
+		}
+	}
+	if format != nil {
+		base = formatTicks{Ticker: base, format: format}
+	}
+	return base
+}
+
+// echartsYTicks returns the y axis of an echarts chart whose values are in [low, high], with the ticks
+// formatted by the YTickFormat option. The ticks are placed in Go, so that the labels can be formatted
+// in Go and looked up by the formatter in the browser.
+func (c *chartConfig) echartsYTicks(y opts.YAxis, low, high float64) opts.YAxis {
+	if c.tickCount > 0 {
+		y.SplitNumber = c.tickCount
+	}
+	if c.yTickFormat == nil {
+		if c.locale != "" {
+			label := copyLabel(y.AxisLabel)
+			label.Formatter = c.localeFormatter()
+			y.AxisLabel = label
+		}
+		return y
+	}
+	// The value axis of echarts starts from zero
+	ticks := niceTicks(min(low, 0), max(high, 0), cmp.Or(c.tickCount, defaultTickCount))
+	if len(ticks) < 2 {
+		return y
+	}
+
+	step := ticks[1] - ticks[0]
+	y.Min, y.Max = ticks[0], ticks[len(ticks)-1]
+	y.MinInterval, y.MaxInterval = step, step
+
+	label := copyLabel(y.AxisLabel)
+	label.Formatter = tickFormatter(ticks, c.yTickFormat)
+	y.AxisLabel = label
+	return y
+}
+
+// echartsXTicks returns the x axis of an echarts chart as a value axis of the numbers of s, or a time axis
+// of its times, see the XValueAxis option. The ticks are formatted like the ones of echartsYTicks.
+func (c *chartConfig) echartsXTicks(x opts.XAxis, s Series) opts.XAxis {
+	// The axis fits the values, instead of starting from zero
+	x.Type, x.Scale = "value", opts.Bool(true)
+	if isTime(s) {
+		x.Type = "time"
+		return x
+	}
+	if c.tickCount > 0 {
+		x.SplitNumber = c.tickCount
+	}
+	if c.xTickFormat == nil {
+		if c.locale != "" {
+			label := copyLabel(x.AxisLabel)
+			label.Formatter = c.localeFormatter()
+			x.AxisLabel = label
+		}
+		return x
+	}
+	values := slices.DeleteFunc(s.ToFloat64(), math.IsNaN)
+	if len(values) == 0 {
+		return x
+	}
+	ticks := niceTicks(slices.Min(values), slices.Max(values), cmp.Or(c.tickCount, defaultTickCount))
+	if len(ticks) < 2 {
+		return x
+	}
+
+	step := ticks[1] - ticks[0]
+	x.Min, x.Max = ticks[0], ticks[len(ticks)-1]
+	x.MinInterval, x.MaxInterval = step, step
+
+	label := copyLabel(x.AxisLabel)
+	label.Formatter = tickFormatter(ticks, c.xTickFormat)
+	x.AxisLabel = label
+	return x
+}
+
+// tickFormatter returns the function which shows the label of the closest tick to a value in the browser,
+// with the labels of the ticks formatted in Go.
+func tickFormatter(ticks []float64, format func(float64) string) types.FuncStr {
+	// The labels are escaped, because the quotes and backslashes in a function are escaped again by go-echarts
+	labels := make([]string, len(ticks))
+	for i, v := range ticks {
+		labels[i] = "'" + url.PathEscape(format(v)) + "'"
+	}
+	values, _ := json.Marshal(ticks)
+	texts := "[" + strings.Join(labels, ", ") + "]"
+
+	return opts.FuncOpts(fmt.Sprintf(
+		`function (value) { var ticks = %s, labels = %s, best = 0; for (var i = 1; i < ticks.length; i++) { if (Math.abs(ticks[i] - value) < Math.abs(ticks[best] - value)) best = i; } return decodeURIComponent(labels[best]); }`,
+		values, texts))
+}
+
+// xyValues returns the values of the series s of an echarts chart, which are paired with the values of
+// the x series on a value axis, see the XValueAxis option.
+func (c *chartConfig) xyValues(x, s Series) []any {
+	if !c.xValueAxis {
+		return s.Data()
+	}
+	values := make([]any, s.Len())
+	for i, v := range s.Data() {
+		var xv any
+		if i < x.Len() {
+			xv = x.Data()[i]
+		}
+		if t, ok := xv.(time.Time); ok {
+			xv = t.UnixMilli()
+		}
+		values[i] = []any{xv, v}
+	}
+	return values
+}
+
+// copyLabel returns a copy of an axis label, which may be shared with the other axis, so that it can be changed.
+func copyLabel(label *opts.AxisLabel) *opts.AxisLabel {
+	var ret opts.AxisLabel
+	if label != nil {
+		ret = *label
+	}
+	return &ret
+}
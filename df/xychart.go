@@ -3,16 +3,21 @@ package df
 import (
 	"bytes"
 	"cmp"
+	"context"
 	"fmt"
 	"image/color"
 	"iter"
 	"log"
 	"math"
+	"slices"
 
 	"github.com/discoverkl/goterm/df/vs"
 	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
 )
 
 // Default height for gonum plot, in pixels
@@ -20,6 +25,9 @@ const DefaultPlotWidth = 0
 const DefaultPlotHeight = 480
 const DefaultPlotRatio = 16.0 / 9.0
 
+// DefaultDPI is the resolution of the PNG image of a gonum chart, which matches a CSS pixel.
+const DefaultDPI = 96
+
 // assuming 96 DPI
 const Inch640px = 640 / 96
 const Inch480px = 480 / 96
@@ -32,6 +40,7 @@ type XYChart struct {
 func defaultConfig() *chartConfig {
 	return &chartConfig{
 		ratio:  DefaultPlotRatio,
+		dpi:    DefaultDPI,
 		width:  DefaultPlotWidth,
 		height: DefaultPlotHeight,
 	}
@@ -46,18 +55,40 @@ func NewGonumPlot(p *plot.Plot) *XYChart {
 }
 
 func NewXY(name string, xx []float64, yy []float64, options ...ChartOption) (*XYChart, error) {
-	return create(name, nil, xx, yy, options...)
+	return create(context.Background(), name, nil, xx, yy, options...)
 }
 
 func NewXYFn(name string, fn func(float64) float64, options ...ChartOption) (*XYChart, error) {
-	return create(name, fn, nil, nil, options...)
+	return create(context.Background(), name, fn, nil, nil, options...)
 }
 
 func NewXYChart(options ...ChartOption) (*XYChart, error) {
-	return create("", nil, nil, nil, options...)
+	return create(context.Background(), "", nil, nil, nil, options...)
+}
+
+// NewXYContext is like NewXY, but stops computing the points when the context is done, and returns its error.
+func NewXYContext(ctx context.Context, name string, xx []float64, yy []float64, options ...ChartOption) (*XYChart, error) {
+	return create(ctx, name, nil, xx, yy, options...)
+}
+
+// NewXYFnContext is like NewXYFn, but stops calling fn when the context is done, and returns its error.
+// It's useful to abort a chart of a costly function, e.g. when the user leaves the page.
+func NewXYFnContext(ctx context.Context, name string, fn func(float64) float64, options ...ChartOption) (*XYChart, error) {
+	return create(ctx, name, fn, nil, nil, options...)
+}
+
+// NewXYChartContext is like NewXYChart, but stops computing the points when the context is done, and returns its error.
+func NewXYChartContext(ctx context.Context, options ...ChartOption) (*XYChart, error) {
+	return create(ctx, "", nil, nil, nil, options...)
+}
+
+// Band creates an XY chart which shades the area between the lower and upper lines, see BandXY.
+func Band(x, lower, upper []float64, options ...ChartOption) (*XYChart, error) {
+	options = append([]ChartOption{BandXY("", x, lower, upper)}, options...)
+	return create(context.Background(), "", nil, nil, nil, options...)
 }
 
-func create(name string, fn func(float64) float64, xx []float64, yy []float64, options ...ChartOption) (*XYChart, error) {
+func create(ctx context.Context, name string, fn func(float64) float64, xx []float64, yy []float64, options ...ChartOption) (*XYChart, error) {
 	var err error
 	// Create a new plot
 	p := plot.New()
@@ -69,11 +100,12 @@ func create(name string, fn func(float64) float64, xx []float64, yy []float64, o
 	}
 
 	p.Title.Text = cmp.Or(c.conf.name, name)
-	p.Title.TextStyle.Font.Size = vg.Points(16)
+	p.Title.TextStyle.Font.Size = vg.Points(cmp.Or(c.conf.titleFontSize, 16))
 	p.Title.Padding = vg.Points(10)
 	p.X.Label.Text = cmp.Or(c.conf.xLabel, "X")
 	p.Y.Label.Text = cmp.Or(c.conf.yLabel, "Y")
 
+	themeMu.RLock()
 	p.Title.TextStyle.Color = textColor
 	p.BackgroundColor = color.Transparent
 	p.X.Color = axisLineColor
@@ -85,6 +117,19 @@ func create(name string, fn func(float64) float64, xx []float64, yy []float64, o
 	p.X.Tick.Label.Color = textColor
 	p.Y.Tick.Label.Color = textColor
 	p.Legend.TextStyle.Color = legendTextColor
+	themeMu.RUnlock()
+	c.setFonts()
+
+	if c.conf.timeFormat != "" {
+		p.X.Tick.Marker = plot.TimeTicks{Format: c.conf.timeFormat}
+	}
+	xFormat := c.conf.xTickFormat
+	if c.conf.timeFormat == "" {
+		// Times keep their layout
+		xFormat = c.conf.tickFormat(xFormat)
+	}
+	p.X.Tick.Marker = c.conf.ticker(p.X.Tick.Marker, xFormat)
+	p.Y.Tick.Marker = c.conf.ticker(p.Y.Tick.Marker, c.conf.tickFormat(c.conf.yTickFormat))
 
 	// Disable automatic padding to center Y-axis
 	p.X.Padding = 0
@@ -96,6 +141,15 @@ func create(name string, fn func(float64) float64, xx []float64, yy []float64, o
 		linesConfig = append(linesConfig, &LineData{Name: name, Fn: fn, X: xx, Y: yy})
 	}
 	linesConfig = append(linesConfig, c.conf.lines...)
+	for i, line := range linesConfig {
+		if style, ok := c.conf.lineStyles[line.Name]; ok {
+			styled := *line
+			styled.Width, styled.Dashes = style.width, style.dashes
+			linesConfig[i] = &styled
+		}
+	}
+
+	labels := lineLabels(linesConfig)
 
 	// Parse lines to sequences
 	seqs := []iter.Seq2[float64, float64]{}
@@ -109,32 +163,125 @@ func create(name string, fn func(float64) float64, xx []float64, yy []float64, o
 		seqs = append(seqs, points)
 	}
 
-	// Create series
+	// Create series, and the error bars of the points which are kept. The points of a line are split
+	// into segments at the invalid points in the break mode.
 	series := []plotter.XYer{}
-	for _, seq := range seqs {
-		pts := []plotter.XY{}
+	yerrs := []plotter.YErrors{}
+	segments := [][]plotter.XYs{}
+	for i, seq := range seqs {
+		parts := []*pointSegment{{}}
+		j := -1
 		for x, y := range seq {
+			// Each point may call a costly function
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			j++
 			if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+				switch c.conf.onInvalid {
+				case InvalidError:
+					return nil, fmt.Errorf("invalid point %d of %s: (%g, %g)", j, labels[i], x, y)
+				case InvalidBreak:
+					if len(parts[len(parts)-1].pts) > 0 {
+						parts = append(parts, &pointSegment{})
+					}
+				}
+				continue
+			}
+			part := parts[len(parts)-1]
+			part.pts = append(part.pts, plotter.XY{X: x, Y: y})
+			if e := linesConfig[i].YErr; e != nil {
+				var v float64
+				if j < len(e) {
+					v = e[j]
+				}
+				part.yerr = append(part.yerr, struct{ Low, High float64 }{v, v})
+			}
+		}
+
+		if n := c.conf.maxPoints; n > 0 {
+			downsampleSegments(parts, max(n, 3))
+		}
+		var pts plotter.XYs
+		var yerr plotter.YErrors
+		var lines []plotter.XYs
+		for _, part := range parts {
+			// A short segment may have no point left after downsampling
+			if len(part.pts) == 0 && len(parts) > 1 {
 				continue
 			}
-			pts = append(pts, plotter.XY{X: x, Y: y})
+			pts = append(pts, part.pts...)
+			yerr = append(yerr, part.yerr...)
+			lines = append(lines, part.pts)
 		}
-		xys := plotter.XYs(pts)
-		series = append(series, xys)
+		series = append(series, pts)
+		yerrs = append(yerrs, yerr)
+		segments = append(segments, lines)
 	}
 
 	// Set ranges for axes
 	c.adjustXYRange(series...)
 
+	// Draw the bands under the lines
+	err = c.drawBands()
+	if err != nil {
+		return nil, err
+	}
+
 	// Draw the function
 	for i, xys := range series {
-		line, err := plotter.NewLine(xys)
-		if err != nil {
-			return nil, err
+		if linesConfig[i].Scatter {
+			scatter, err := plotter.NewScatter(xys)
+			if err != nil {
+				return nil, err
+			}
+			scatter.GlyphStyle.Color = getColor(i)
+			scatter.GlyphStyle.Shape = draw.CircleGlyph{}
+			p.Add(scatter)
+			p.Legend.Add(labels[i], scatter)
+			continue
+		}
+
+		// Each segment is a line of its own, and the first one is in the legend
+		for k, part := range segments[i] {
+			var line *plotter.Line
+			if c.conf.smooth && c.conf.step == "" {
+				line, err = plotter.NewLine(smoothPoints(part, smoothSteps))
+			} else {
+				line, err = plotter.NewLine(part)
+			}
+			if err != nil {
+				return nil, err
+			}
+			line.Color = getColor(i)
+			line.StepStyle = stepKinds[c.conf.step]
+			if w := linesConfig[i].Width; w > 0 {
+				line.LineStyle.Width = vg.Points(w)
+			}
+			for _, d := range linesConfig[i].Dashes {
+				line.LineStyle.Dashes = append(line.LineStyle.Dashes, vg.Points(d))
+			}
+			p.Add(line)
+			if k == 0 {
+				p.Legend.Add(labels[i], line)
+			}
+		}
+
+		// Draw the error bars of the line
+		if yerrs[i] != nil {
+			bars, err := plotter.NewYErrorBars(errorPoints{XYer: xys, YErrors: yerrs[i]})
+			if err != nil {
+				return nil, err
+			}
+			bars.Color = getColor(i)
+			p.Add(bars)
 		}
-		line.Color = getColor(i)
-		p.Add(line)
-		p.Legend.Add(cmp.Or(linesConfig[i].Name, fmt.Sprintf("Line %d", i)), line)
+	}
+
+	// Add reference lines
+	err = c.drawMarkLines(len(series))
+	if err != nil {
+		return nil, err
 	}
 
 	// Add zero lines
@@ -143,15 +290,29 @@ func create(name string, fn func(float64) float64, xx []float64, yy []float64, o
 		return nil, err
 	}
 
+	if c.conf.minimal {
+		c.minimize()
+	}
 	return c, nil
 }
 
+// minimize hides the title, the axis names, the ticks and the legend, see the Minimal option.
+func (c *XYChart) minimize() {
+	p := c.gp
+	p.Title.Text = ""
+	p.X.Label.Text = ""
+	p.Y.Label.Text = ""
+	p.HideAxes()
+	p.Legend = plot.NewLegend()
+}
+
 func (c *XYChart) HTML() string {
 	p := c.gp
 	var buf bytes.Buffer
 
 	buf.WriteString(`<div style="padding: 16px; box-sizing: border-box">`)
-	wt, err := p.WriterTo(Inch480px*vg.Length(c.conf.ratio)*vg.Inch, Inch480px*vg.Inch, "svg")
+	width, height := c.size()
+	wt, err := p.WriterTo(width, height, "svg")
 	if err != nil {
 		log.Printf("print plot failed: %v", err)
 		return ""
@@ -161,6 +322,27 @@ func (c *XYChart) HTML() string {
 	return buf.String()
 }
 
+// size returns the width and the height of the chart.
+// size returns the size of the chart, which is DefaultPlotHeight CSS pixels high. A CSS pixel is 1/96 inch,
+// whatever the DPI option is, which only sets the pixels of the PNG image.
+func (c *XYChart) size() (width, height vg.Length) {
+	height = DefaultPlotHeight * vg.Inch / 96
+	return height * vg.Length(c.conf.ratio), height
+}
+
+// PNG renders the chart as a PNG image, with the resolution set by the DPI option.
+func (c *XYChart) PNG() ([]byte, error) {
+	width, height := c.size()
+	canvas := vgimg.NewWith(vgimg.UseWH(width, height), vgimg.UseDPI(cmp.Or(c.conf.dpi, DefaultDPI)))
+	c.gp.Draw(draw.New(canvas))
+
+	var buf bytes.Buffer
+	if _, err := (vgimg.PngCanvas{Canvas: canvas}).WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (c *XYChart) adjustXYRange(data ...plotter.XYer) {
 	p := c.gp
 	var xMin, xMax, yMin, yMax float64
@@ -179,10 +361,66 @@ func (c *XYChart) adjustXYRange(data ...plotter.XYer) {
 	p.Y.Max = max(1, yMax)
 }
 
+// drawBands fills the bands with the colors of the palette, with a reduced opacity.
+func (c *XYChart) drawBands() error {
+	p := c.gp
+	for i, band := range c.conf.bands {
+		// The outline goes along the lower line and back along the upper line
+		var pts plotter.XYs
+		for j := 0; j < min(len(band.x), len(band.lower)); j++ {
+			pts = appendValidPoint(pts, band.x[j], band.lower[j])
+		}
+		for j := min(len(band.x), len(band.upper)) - 1; j >= 0; j-- {
+			pts = appendValidPoint(pts, band.x[j], band.upper[j])
+		}
+		if len(pts) == 0 {
+			continue
+		}
+
+		polygon, err := plotter.NewPolygon(pts)
+		if err != nil {
+			return err
+		}
+		r, g, b, _ := getColor(i).RGBA()
+		polygon.Color = color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 0x40}
+		polygon.LineStyle.Width = 0
+		p.Add(polygon)
+		p.Legend.Add(cmp.Or(band.name, fmt.Sprintf("Band %d", i)), polygon)
+	}
+	return nil
+}
+
+// appendValidPoint appends the point if both coordinates are finite numbers.
+func appendValidPoint(pts plotter.XYs, x, y float64) plotter.XYs {
+	if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return pts
+	}
+	return append(pts, plotter.XY{X: x, Y: y})
+}
+
+// drawMarkLines draws the horizontal reference lines across the x range, with colors after the ones of the n lines.
+func (c *XYChart) drawMarkLines(n int) error {
+	p := c.gp
+	for i, m := range c.conf.markLines {
+		markLine, err := plotter.NewLine(plotter.XYs{{X: p.X.Min, Y: m.value}, {X: p.X.Max, Y: m.value}})
+		if err != nil {
+			return err
+		}
+		markLine.LineStyle.Dashes = []vg.Length{vg.Points(5), vg.Points(5)} // Dashed line
+		markLine.Color = getColor(n + i)
+		p.Add(markLine)
+		p.Legend.Add(cmp.Or(m.label, fmt.Sprintf("y = %g", m.value)), markLine)
+	}
+	return nil
+}
+
 func (c *XYChart) drawZeroLines() error {
 	p := c.gp
 	var zeroLine *plotter.Line
 	var err error
+	themeMu.RLock()
+	lineColor := axisLineColor
+	themeMu.RUnlock()
 
 	// Add a vertical line at X=0 to emphasize the middle Y-axis
 	if p.X.Min < 0 {
@@ -192,7 +430,7 @@ func (c *XYChart) drawZeroLines() error {
 		}
 		zeroLine.LineStyle.Width = vg.Points(0.5)
 		zeroLine.LineStyle.Dashes = []vg.Length{vg.Points(5), vg.Points(5)} // Dashed line
-		zeroLine.Color = axisLineColor
+		zeroLine.Color = lineColor
 		p.Add(zeroLine)
 	}
 
@@ -204,12 +442,232 @@ func (c *XYChart) drawZeroLines() error {
 		}
 		zeroLine.LineStyle.Width = vg.Points(0.5)
 		zeroLine.LineStyle.Dashes = []vg.Length{vg.Points(5), vg.Points(5)} // Dashed line
-		zeroLine.Color = axisLineColor
+		zeroLine.Color = lineColor
 		p.Add(zeroLine)
 	}
 	return nil
 }
 
+// setFonts applies the font options to the text of the plot.
+func (c *XYChart) setFonts() {
+	p := c.gp
+	if size := vg.Points(c.conf.axisFontSize); size > 0 {
+		p.X.Label.TextStyle.Font.Size = size
+		p.Y.Label.TextStyle.Font.Size = size
+		p.X.Tick.Label.Font.Size = size
+		p.Y.Tick.Label.Font.Size = size
+	}
+
+	// The default fonts of gonum have the Serif, Sans and Mono variants
+	var variant font.Variant
+	switch c.conf.fontFamily {
+	case "serif":
+		variant = "Serif"
+	case "sans-serif":
+		variant = "Sans"
+	case "monospace":
+		variant = "Mono"
+	default:
+		return
+	}
+	for _, f := range []*font.Font{
+		&p.Title.TextStyle.Font,
+		&p.X.Label.TextStyle.Font,
+		&p.Y.Label.TextStyle.Font,
+		&p.X.Tick.Label.Font,
+		&p.Y.Tick.Label.Font,
+		&p.Legend.TextStyle.Font,
+	} {
+		f.Variant = variant
+	}
+}
+
+// stepKinds are the gonum step styles of the positions of the Step option.
+var stepKinds = map[string]plotter.StepKind{
+	"start":  plotter.PreStep,
+	"middle": plotter.MidStep,
+	"end":    plotter.PostStep,
+}
+
+// lineLabels returns the labels of the lines in the legend. A line without a name is labeled "Line 1", "Line 2"
+// and so on, skipping the labels which are the names of other lines, so that all labels are different.
+func lineLabels(lines []*LineData) []string {
+	names := map[string]bool{}
+	for _, line := range lines {
+		names[line.Name] = true
+	}
+	labels := make([]string, len(lines))
+	n := 0
+	for i, line := range lines {
+		if line.Name != "" {
+			labels[i] = line.Name
+			continue
+		}
+		for {
+			n++
+			if label := fmt.Sprintf("Line %d", n); !names[label] {
+				labels[i] = label
+				break
+			}
+		}
+	}
+	return labels
+}
+
+// pointSegment is a part of a line between two invalid points, with the error bars of its points.
+type pointSegment struct {
+	pts  plotter.XYs
+	yerr plotter.YErrors
+}
+
+// downsampleSegments downsamples the segments of a line to n points in total if there are more. Each segment
+// gets a share of the n points in proportion to its size, so a short segment may have no point left.
+func downsampleSegments(parts []*pointSegment, n int) {
+	sizes := make([]int, len(parts))
+	total := 0
+	for i, part := range parts {
+		sizes[i] = len(part.pts)
+		total += sizes[i]
+	}
+	if total <= n {
+		return
+	}
+	for i, k := range shares(sizes, n) {
+		part := parts[i]
+		keep := downsample(part.pts, k)
+		part.pts = pick(part.pts, keep)
+		if part.yerr != nil {
+			part.yerr = pick(part.yerr, keep)
+		}
+	}
+}
+
+// shares splits n into shares in proportion to the sizes, by the largest remainder method, so that the sum
+// of the shares is n. n must not be larger than the sum of the sizes.
+func shares(sizes []int, n int) []int {
+	total := 0
+	for _, size := range sizes {
+		total += size
+	}
+	ret := make([]int, len(sizes))
+	rest := n
+	for i, size := range sizes {
+		ret[i] = n * size / total
+		rest -= ret[i]
+	}
+
+	// The remaining points go to the largest remainders
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(n*sizes[b]%total, n*sizes[a]%total)
+	})
+	for _, i := range order[:rest] {
+		ret[i]++
+	}
+	return ret
+}
+
+// downsample returns the indices of n points which keep the shape of the line, by the Largest-Triangle-Three-Buckets
+// algorithm. The first and the last points are kept, and the others are split into n-2 buckets. The point kept
+// from a bucket makes the largest triangle with the point kept from the previous bucket and the average of the
+// next bucket. For n < 3, it keeps the first point, and the last point for n = 2.
+func downsample(pts []plotter.XY, n int) []int {
+	if len(pts) <= n {
+		keep := make([]int, len(pts))
+		for i := range keep {
+			keep[i] = i
+		}
+		return keep
+	}
+	switch n {
+	case 0:
+		return nil
+	case 1:
+		return []int{0}
+	case 2:
+		return []int{0, len(pts) - 1}
+	}
+
+	size := float64(len(pts)-2) / float64(n-2)
+	bucket := func(i int) int {
+		return min(int(float64(i)*size)+1, len(pts)-1)
+	}
+	keep := []int{0}
+	a := 0
+	for i := 0; i < n-2; i++ {
+		// The average of the next bucket, which is the last point for the last bucket
+		var avgX, avgY float64
+		start, end := bucket(i+1), max(bucket(i+2), bucket(i+1)+1)
+		for _, p := range pts[start:end] {
+			avgX += p.X
+			avgY += p.Y
+		}
+		avgX /= float64(end - start)
+		avgY /= float64(end - start)
+
+		best, bestArea := bucket(i), -1.0
+		for j := bucket(i); j < bucket(i+1); j++ {
+			// Twice the area of the triangle, which is enough to compare
+			area := math.Abs((pts[a].X-avgX)*(pts[j].Y-pts[a].Y) - (pts[a].X-pts[j].X)*(avgY-pts[a].Y))
+			if area > bestArea {
+				best, bestArea = j, area
+			}
+		}
+		keep = append(keep, best)
+		a = best
+	}
+	return append(keep, len(pts)-1)
+}
+
+// pick returns the elements of s at the indices.
+func pick[S ~[]E, E any](s S, indices []int) S {
+	ret := make(S, len(indices))
+	for i, j := range indices {
+		ret[i] = s[j]
+	}
+	return ret
+}
+
+// smoothSteps is the number of segments of the curve between two points of a smooth line.
+const smoothSteps = 8
+
+// smoothPoints interpolates the points with a Catmull-Rom spline, which passes through all of them,
+// with the given number of segments between two points.
+func smoothPoints(xys plotter.XYer, steps int) plotter.XYs {
+	pts, _ := plotter.CopyXYs(xys)
+	if len(pts) < 3 {
+		return pts
+	}
+	var curve plotter.XYs
+	for i := 0; i < len(pts)-1; i++ {
+		// The ends are repeated for the tangents of the first and the last segments
+		p0, p1, p2, p3 := pts[max(i-1, 0)], pts[i], pts[i+1], pts[min(i+2, len(pts)-1)]
+		for j := 0; j < steps; j++ {
+			t := float64(j) / float64(steps)
+			curve = append(curve, plotter.XY{
+				X: catmullRom(p0.X, p1.X, p2.X, p3.X, t),
+				Y: catmullRom(p0.Y, p1.Y, p2.Y, p3.Y, t),
+			})
+		}
+	}
+	return append(curve, pts[len(pts)-1])
+}
+
+// catmullRom returns the value at t in [0, 1] of the segment from p1 to p2 of a Catmull-Rom spline.
+func catmullRom(p0, p1, p2, p3, t float64) float64 {
+	t2, t3 := t*t, t*t*t
+	return 0.5 * (2*p1 + (p2-p0)*t + (2*p0-5*p1+4*p2-p3)*t2 + (3*p1-p0-3*p2+p3)*t3)
+}
+
+// errorPoints are the points of a line with their y errors.
+type errorPoints struct {
+	plotter.XYer
+	plotter.YErrors
+}
+
 func getPoints(c *XYChart, fn func(float64) float64) iter.Seq2[float64, float64] {
 	return func(yield func(float64, float64) bool) {
 		plotX := c.conf.plotX
@@ -256,20 +714,10 @@ func getColor(i int) color.Color {
 }
 
 var palette = getPalette()
-var textColor = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
-var legendTextColor = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
-var axisLineColor = color.RGBA{R: 0x6e, G: 0x70, B: 0x79, A: 0xff}
-var axisTickColor = color.RGBA{R: 0x6e, G: 0x70, B: 0x79, A: 0xff}
-var axisLabelColor = color.RGBA{R: 0x6e, G: 0x70, B: 0x79, A: 0xff}
-
-func init() {
-	// set colors
-	mock := false
-	if mock {
-		textColor = color.RGBA{R: 0xff, A: 0xff}
-		legendTextColor = textColor
-		axisLineColor = textColor
-		axisTickColor = textColor
-		axisLabelColor = textColor
-	}
-}
+
+// Colors of the light theme, see SetChartTheme
+var textColor color.Color = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+var legendTextColor color.Color = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+var axisLineColor color.Color = color.RGBA{R: 0x6e, G: 0x70, B: 0x79, A: 0xff}
+var axisTickColor color.Color = color.RGBA{R: 0x6e, G: 0x70, B: 0x79, A: 0xff}
+var axisLabelColor color.Color = color.RGBA{R: 0x6e, G: 0x70, B: 0x79, A: 0xff}
@@ -0,0 +1,224 @@
+package df
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/color"
+	"image/png"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"gonum.org/v1/plot/plotter"
+)
+
+func TestNewXYFnContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	fn := func(x float64) float64 {
+		calls++
+		if calls == 10 {
+			cancel()
+		}
+		return x
+	}
+
+	_, err := NewXYFnContext(ctx, "slow", fn)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	if calls != 10 {
+		t.Errorf("fn was called %d times after the cancellation, want none", calls-10)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	// A flat line with a spike, which must be kept
+	var pts []plotter.XY
+	for i := 0; i < 1000; i++ {
+		pts = append(pts, plotter.XY{X: float64(i)})
+	}
+	pts[500].Y = 100
+
+	keep := downsample(pts, 10)
+	if len(keep) != 10 {
+		t.Fatalf("got %d points, want 10", len(keep))
+	}
+	if keep[0] != 0 || keep[len(keep)-1] != 999 {
+		t.Errorf("the first and the last points are not kept: %v", keep)
+	}
+	if !slices.Contains(keep, 500) {
+		t.Errorf("the spike is not kept: %v", keep)
+	}
+	if !slices.IsSorted(keep) {
+		t.Errorf("the points are not in order: %v", keep)
+	}
+
+	if got := downsample(pts[:5], 10); len(got) != 5 {
+		t.Errorf("got %d points of 5 points, want all of them", len(got))
+	}
+}
+
+func TestDownsampleSegments(t *testing.T) {
+	// A line of 1000 points which is broken into segments of 4 points, and a long segment
+	var parts []*pointSegment
+	for i := 0; i < 200; i++ {
+		parts = append(parts, &pointSegment{pts: make(plotter.XYs, 4)})
+	}
+	parts = append(parts, &pointSegment{pts: make(plotter.XYs, 200)})
+
+	downsampleSegments(parts, 100)
+	total := 0
+	for _, part := range parts {
+		total += len(part.pts)
+	}
+	if total != 100 {
+		t.Errorf("got %d points, want 100", total)
+	}
+	if got, want := len(parts[len(parts)-1].pts), 20; got != want {
+		t.Errorf("got %d points of the long segment, want its share %d", got, want)
+	}
+
+	if got, want := shares([]int{1, 1, 1}, 2), []int{1, 1, 0}; !slices.Equal(got, want) {
+		t.Errorf("got shares %v, want %v", got, want)
+	}
+}
+
+func TestOnInvalid(t *testing.T) {
+	x := []float64{0, 1, 2, 3, 4}
+	y := []float64{0, 1, math.NaN(), 3, 4}
+
+	// A line is a path of the SVG, so a broken line has one more path
+	paths := map[InvalidMode]int{}
+	for _, mode := range []InvalidMode{InvalidSkip, InvalidBreak} {
+		c, err := NewXY("y", x, y, OnInvalid(mode))
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		paths[mode] = strings.Count(c.HTML(), "<path")
+	}
+	if paths[InvalidBreak] != paths[InvalidSkip]+1 {
+		t.Errorf("got %d paths for a broken line, want %d", paths[InvalidBreak], paths[InvalidSkip]+1)
+	}
+
+	if _, err := NewXY("y", x, y, OnInvalid(InvalidError)); err == nil {
+		t.Error("got no error for a NaN point")
+	}
+}
+
+func TestOnInvalidMaxPoints(t *testing.T) {
+	// A line of 1000 points which is broken into three segments by NaN values
+	x := make([]float64, 1000)
+	y := make([]float64, 1000)
+	for i := range x {
+		x[i] = float64(i)
+		y[i] = math.Sin(float64(i) / 50)
+	}
+	y[400] = math.NaN()
+	y[600] = math.NaN()
+
+	c, err := NewXY("y", x, y, OnInvalid(InvalidBreak), MaxPoints(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each segment is a path in the color of the line, and the legend has one more of 2 points
+	var sizes []int
+	for _, line := range strings.Split(c.HTML(), "\n") {
+		if strings.HasPrefix(line, "<path") && strings.Contains(line, "stroke:#5470C6\"") {
+			sizes = append(sizes, strings.Count(line, "M")+strings.Count(line, "L"))
+		}
+	}
+	if got, want := sizes, []int{40, 20, 40, 2}; !slices.Equal(got, want) {
+		t.Errorf("got paths of %v points, want %v", got, want)
+	}
+}
+
+func TestLineLabels(t *testing.T) {
+	square := func(x float64) float64 { return x * x }
+	c, err := NewXYChart(LineFn("", math.Sin), LineFn("", square))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := c.HTML()
+	for _, label := range []string{"Line 1", "Line 2"} {
+		if !strings.Contains(svg, label) {
+			t.Errorf("the legend has no %q", label)
+		}
+	}
+
+	// The names win, and the default labels are different from them
+	lines := []*LineData{{Name: "Line 1"}, {}, {Name: "cos"}, {}}
+	want := []string{"Line 1", "Line 2", "cos", "Line 3"}
+	if got := lineLabels(lines); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPNGSize(t *testing.T) {
+	for _, tc := range []struct {
+		options       []ChartOption
+		width, height int
+	}{
+		{nil, 853, 480},
+		{[]ChartOption{DPI(192)}, 1707, 960},
+		{[]ChartOption{DPI(48), Ratio(2)}, 480, 240},
+	} {
+		c, err := NewXYChart(append([]ChartOption{LineXY("a", []float64{0, 1}, []float64{0, 1})}, tc.options...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := c.PNG()
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Width != tc.width || img.Height != tc.height {
+			t.Errorf("got %dx%d, want %dx%d", img.Width, img.Height, tc.width, tc.height)
+		}
+	}
+}
+
+func TestChartTheme(t *testing.T) {
+	defer SetChartTheme(LightTheme)
+	line := LineXY("a", []float64{0, 1}, []float64{0, 1})
+	light, err := NewXYChart(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Setting the theme while charts are created is safe
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			SetChartTheme([]ChartTheme{LightTheme, DarkTheme}[i%2])
+			NewXYChart(line)
+			echartTheme()
+		}()
+	}
+	wg.Wait()
+
+	SetChartTheme(DarkTheme)
+	dark, err := NewXYChart(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dark.gp.Title.TextStyle.Color; got != textColor || got == light.gp.Title.TextStyle.Color {
+		t.Errorf("got the title color %v, want the dark one", got)
+	}
+	// A chart keeps the theme it's created with
+	if got, want := light.gp.Title.TextStyle.Color, (color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}); got != want {
+		t.Errorf("got the title color %v, want %v", got, want)
+	}
+	if got := echartTheme(); got != "dark" {
+		t.Errorf("got the echarts theme %q, want dark", got)
+	}
+}
@@ -6,6 +6,7 @@ toolchain go1.23.4
 
 require (
 	github.com/go-echarts/go-echarts/v2 v2.4.6
+	golang.org/x/text v0.14.0
 	gonum.org/v1/plot v0.14.0
 )
 
@@ -20,6 +21,5 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/exp v0.0.0-20231110203233-9a3e6036ecaa // indirect
 	golang.org/x/image v0.14.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 	gonum.org/v1/gonum v0.15.1 // indirect
 )
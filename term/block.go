@@ -1,10 +1,12 @@
 package term
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
 	"html"
 	"image/color"
+	"regexp"
 	"strings"
 )
 
@@ -24,12 +26,40 @@ type BlockWithOption interface {
 
 type BlockOption func(*blockConfig)
 
+// Align is the horizontal alignment of a block in its row.
+type Align int
+
+const (
+	AlignCenter Align = iota // Center the block, the default
+	AlignLeft                // Align the block to the left, such as a paragraph of text
+	AlignRight               // Align the block to the right
+)
+
 type blockConfig struct {
 	width      int
 	height     int
 	background color.Color
 	color      color.Color
 	opacity    *float64
+
+	align Align
+
+	// fill makes the box take up the full width of the row, like an iframe
+	fill bool
+
+	// spacing of the row and the box, in pixels: top, right, bottom and left
+	margin  *[4]int
+	padding *[4]int
+
+	// border of the box, its width is 0 for no border
+	borderWidth int
+	borderColor color.Color
+	radius      int
+
+	// sandbox is the sandbox attribute of an iframe, nil for no sandbox
+	sandbox *string
+	// csp is the Content-Security-Policy of the page of an iframe
+	csp string
 }
 
 func SizeOption(width, height int) BlockOption {
@@ -39,35 +69,108 @@ func SizeOption(width, height int) BlockOption {
 	}
 }
 
+// BackgroundOption sets the background color of the row of a block. The page of an iframe block is
+// transparent by default, so the color shows through unless the page sets its own background.
 func BackgroundOption(c color.Color) BlockOption {
 	return func(conf *blockConfig) {
 		conf.background = c
 	}
 }
 
+// ColorOption sets the text color of a block. For an iframe block, it's set to the body of its page.
 func ColorOption(c color.Color) BlockOption {
 	return func(conf *blockConfig) {
 		conf.color = c
 	}
 }
 
+// OpacityOption sets the opacity of a block, in [0, 1]. It applies to an iframe block as a whole.
 func OpacityOption(x float64) BlockOption {
 	return func(conf *blockConfig) {
 		conf.opacity = &x
 	}
 }
 
+// SandboxOption sets the sandbox attribute of the iframe of a block, which restricts what its page can do.
+// An empty policy is the most restrictive, while "allow-scripts" lets the scripts, such as the ones of
+// an echarts chart, run in a separate origin which can't access the terminal page.
+func SandboxOption(policy string) BlockOption {
+	return func(conf *blockConfig) {
+		conf.sandbox = &policy
+	}
+}
+
+// CSPOption adds a Content-Security-Policy meta element to the head of the page of an iframe block,
+// e.g. "script-src 'none'" to block the scripts of untrusted content. It doesn't apply to an iframe of a URL.
+func CSPOption(policy string) BlockOption {
+	return func(conf *blockConfig) {
+		conf.csp = policy
+	}
+}
+
+// AlignOption sets the horizontal alignment of a block in its row, which is centered by default.
+func AlignOption(align Align) BlockOption {
+	return func(conf *blockConfig) {
+		conf.align = align
+	}
+}
+
+// MarginOption sets the space around the row of a block, in pixels, to space out the blocks of a report.
+func MarginOption(top, right, bottom, left int) BlockOption {
+	return func(conf *blockConfig) {
+		conf.margin = &[4]int{top, right, bottom, left}
+	}
+}
+
+// PaddingOption sets the space between the box of a block and its content, in pixels.
+// The padding is included in the size of the box, so a box which fills the row doesn't overflow it.
+func PaddingOption(top, right, bottom, left int) BlockOption {
+	return func(conf *blockConfig) {
+		conf.padding = &[4]int{top, right, bottom, left}
+	}
+}
+
+// BorderOption draws a solid border of the given width in pixels around the box of a block,
+// which frames it like a card with MarginOption, PaddingOption and RadiusOption.
+func BorderOption(width int, c color.Color) BlockOption {
+	return func(conf *blockConfig) {
+		conf.borderWidth = width
+		conf.borderColor = c
+	}
+}
+
+// RadiusOption rounds the corners of the box of a block, in pixels.
+func RadiusOption(px int) BlockOption {
+	return func(conf *blockConfig) {
+		conf.radius = px
+	}
+}
+
+// spacingCSS returns the css of the margin or the padding of the given sides.
+func spacingCSS(property string, sides *[4]int) string {
+	return fmt.Sprintf("%s: %dpx %dpx %dpx %dpx;", property, sides[0], sides[1], sides[2], sides[3])
+}
+
+// fillOption makes a block take up the full width of the row.
+func fillOption(c *blockConfig) {
+	c.fill = true
+}
+
 func Block(e BlockElement, ops ...BlockOption) {
 	BlockSize(e, 0, 0, ops...)
 }
 
 func BlockSize(e BlockElement, width, height int, ops ...BlockOption) {
+	PrintBlockSize(e.HTML(), width, height, elementOptions(e, ops...)...)
+}
+
+// elementOptions prepends the default options of BlockWithOption elements to the given options.
+func elementOptions(e BlockElement, ops ...BlockOption) []BlockOption {
 	if block, ok := e.(BlockWithOption); ok {
 		// Apply default options for BlockWithOption elements.
-		// log.Println(true)
 		ops = append(block.Options(), ops...)
 	}
-	PrintBlockSize(e.HTML(), width, height, ops...)
+	return ops
 }
 
 func PrintBlock(html string, ops ...BlockOption) {
@@ -76,11 +179,57 @@ func PrintBlock(html string, ops ...BlockOption) {
 
 // PrintBlockSize supports HTML Page, Iframe, and other HTML elements.
 func PrintBlockSize(html string, width, height int, ops ...BlockOption) {
+	block := blockHTML(html, width, height, ops...)
+	term.hooks.run(&term.hooks.blocks, block)
+	PrintHtml(block)
+}
+
+// BlockPage returns a standalone HTML page which contains only the given block element,
+// with the same styles as the terminal page. It's useful to save a chart to a file.
+func BlockPage(e BlockElement, ops ...BlockOption) string {
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html>\n")
+	buf.WriteString("<html>\n")
+	buf.WriteString("<head>\n")
+	buf.WriteString("<meta charset=\"UTF-8\">\n")
+	buf.WriteString("<title>Term</title>\n")
+	buf.WriteString(pageStyle())
+	buf.WriteString(IframeResizeScript)
+	buf.WriteString("</head>\n")
+	buf.WriteString("<body>\n")
+	buf.WriteString(blockHTML(e.HTML(), 0, 0, elementOptions(e, ops...)...))
+	buf.WriteString("\n</body>\n")
+	buf.WriteString("</html>\n")
+	return buf.String()
+}
+
+// blockHTML wraps the given HTML content in a row and a box element.
+func blockHTML(html string, width, height int, ops ...BlockOption) string {
 	var conf blockConfig
 	for _, op := range ops {
 		op(&conf)
 	}
 
+	// config row style
+	var row string
+	if conf.background != nil {
+		row += fmt.Sprintf("background-color: %s;", colorToCSS(conf.background))
+	}
+	if conf.margin != nil {
+		row += spacingCSS("margin", conf.margin)
+	}
+	switch conf.align {
+	case AlignLeft:
+		row += "justify-content: flex-start;"
+	case AlignRight:
+		row += "justify-content: flex-end;"
+	}
+
+	return fmt.Sprintf("<div class='goterm-row'%s>%s</div>", styleAttr(row), boxHTML(html, width, height, &conf))
+}
+
+// boxHTML wraps the given HTML content in a box element which respects the size of the content.
+func boxHTML(html string, width, height int, conf *blockConfig) string {
 	if width == 0 {
 		width = conf.width
 	}
@@ -89,12 +238,6 @@ func PrintBlockSize(html string, width, height int, ops ...BlockOption) {
 		height = conf.height
 	}
 
-	// config row style
-	var row string
-	if conf.background != nil {
-		row += fmt.Sprintf("background-color: %s;", colorToCSS(conf.background))
-	}
-
 	// config box style
 	var css string
 	if width > 0 {
@@ -109,21 +252,101 @@ func PrintBlockSize(html string, width, height int, ops ...BlockOption) {
 	if conf.opacity != nil {
 		css += fmt.Sprintf("opacity: %.2f;", *conf.opacity)
 	}
+	if conf.padding != nil {
+		css += spacingCSS("padding", conf.padding)
+	}
+	if conf.borderWidth > 0 && conf.borderColor != nil {
+		css += fmt.Sprintf("border: %dpx solid %s;", conf.borderWidth, colorToCSS(conf.borderColor))
+	}
+	if conf.radius > 0 {
+		css += fmt.Sprintf("border-radius: %dpx;", conf.radius)
+	}
+	if conf.padding != nil || conf.borderWidth > 0 {
+		// the padding and the border are included in the size, so a box which fills the row doesn't overflow it
+		css += "box-sizing: border-box;"
+	}
 
 	// prompt page html to iframe
 	if strings.HasSuffix(strings.TrimSpace(html), "</html>") {
 		html = EscapeIframe(html, "")
 	}
+	if strings.HasPrefix(html, "<iframe") {
+		html = configIframe(html, conf)
+	}
 
 	// goterm-box: iframe content should default to 100% width and auto overflow-x
 	// TODO: try a more robust way to detect if the content is an iframe
-	if strings.HasPrefix(html, "<iframe") {
+	fill := conf.fill || strings.HasPrefix(html, "<iframe")
+	if fill {
 		css = "width: 100%;" + css
 		css += "overflow-x: auto;"
 	}
-	html = fmt.Sprintf("<div class='goterm-row' style='%s'><div style='%s' class='goterm-box'>%s</div></div>", row, css, html)
-	html = strings.ReplaceAll(html, " style=''", "")
-	PrintHtml(html)
+
+	// goterm-fill: the child fills the width of a box which takes up the row or has a fixed width,
+	// while the child of the other boxes keeps its own size, such as a sized chart in a grid cell
+	klass := "goterm-box"
+	if fill || width > 0 {
+		klass += " goterm-fill"
+	}
+	return fmt.Sprintf("<div%s class='%s'>%s</div>", styleAttr(css), klass, html)
+}
+
+// configIframe applies the options to an iframe tag created by EscapeIframe: the sandbox attribute, and the
+// Content-Security-Policy and the text color which are added to the head of its page.
+func configIframe(iframe string, conf *blockConfig) string {
+	var head string
+	if conf.csp != "" {
+		head += fmt.Sprintf("<meta http-equiv=\"Content-Security-Policy\" content=\"%s\">", html.EscapeString(conf.csp))
+	}
+	if conf.color != nil {
+		// The color is not inherited by the page of an iframe
+		head += fmt.Sprintf("<style>body { color: %s; }</style>", colorToCSS(conf.color))
+	}
+	if head != "" {
+		iframe = injectHead(iframe, escapeForSrcdoc(head))
+	}
+	if conf.sandbox != nil {
+		iframe = fmt.Sprintf(`<iframe sandbox="%s"%s`, html.EscapeString(*conf.sandbox), strings.TrimPrefix(iframe, "<iframe"))
+	}
+	return iframe
+}
+
+// The opening tags of a page escaped for the srcdoc attribute, see escapeForSrcdoc
+var (
+	srcdocHead    = regexp.MustCompile(`(?i)&lt;head(\s.*?)?&gt;`)
+	srcdocHTML    = regexp.MustCompile(`(?i)&lt;html(\s.*?)?&gt;`)
+	srcdocDoctype = regexp.MustCompile(`(?i)&lt;!doctype.*?&gt;`)
+)
+
+// injectHead adds the escaped content to the head of the page in the srcdoc attribute of an iframe tag.
+// A head element is added after the html tag, the doctype or at the start of the page if there is none.
+func injectHead(iframe, content string) string {
+	const attr = `srcdoc="`
+	start := strings.Index(iframe, attr)
+	if start < 0 {
+		return iframe
+	}
+	start += len(attr)
+	page := iframe[start:]
+	if loc := srcdocHead.FindStringIndex(page); loc != nil {
+		return iframe[:start+loc[1]] + content + iframe[start+loc[1]:]
+	}
+	content = escapeForSrcdoc("<head>") + content + escapeForSrcdoc("</head>")
+	pos := 0
+	if loc := srcdocHTML.FindStringIndex(page); loc != nil {
+		pos = loc[1]
+	} else if loc := srcdocDoctype.FindStringIndex(page); loc != nil {
+		pos = loc[1]
+	}
+	return iframe[:start+pos] + content + iframe[start+pos:]
+}
+
+// styleAttr returns a style attribute for the given css, or an empty string if there is no css.
+func styleAttr(css string) string {
+	if css == "" {
+		return ""
+	}
+	return fmt.Sprintf(" style='%s'", css)
 }
 
 type Image string
@@ -140,14 +363,24 @@ func ImageData(mime string, data []byte) BlockElement {
 
 // EscapeIframe wraps the given HTML content in an iframe tag and escapes it for srcdoc attribute.
 // If the pageHtml starts with "http", it will be used as the source url of the iframe.
+// The height of a srcdoc iframe fits its content on a terminal page, unless the block has a fixed height.
 func EscapeIframe(pageHtml string, klass string) string {
 	var attr, value = "src", pageHtml
 	if !strings.HasPrefix(pageHtml, "http") {
-		attr, value = "srcdoc", escapeForSrcdoc(pageHtml)
+		attr, value = "srcdoc", escapeForSrcdoc(injectHeightScript(pageHtml))
 	}
 	return fmt.Sprintf(`<iframe class="%s" %s="%s"></iframe>`, klass, attr, value)
 }
 
+// injectHeightScript adds IframeHeightScript to the end of the body of the page.
+func injectHeightScript(page string) string {
+	i := strings.LastIndex(page, "</body>")
+	if i < 0 {
+		return page + IframeHeightScript
+	}
+	return page[:i] + IframeHeightScript + page[i:]
+}
+
 // escapeForSrcdoc escapes the given content for the srcdoc attribute of an iframe.
 func escapeForSrcdoc(content string) string {
 	// First, escape HTML special characters
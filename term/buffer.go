@@ -1,56 +1,166 @@
 package term
 
-import "io"
+import (
+	"io"
+	"sync"
+)
 
 const bufferSize = 10 * 1024
 
+// maxPooledChunk is the capacity above which a chunk is not put back to the pool, so that a huge write
+// doesn't keep its memory.
+const maxPooledChunk = 64 * 1024
+
+// chunkPool reuses the copies of the written data, which are sent to the channel.
+var chunkPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 4096)
+		return &b
+	},
+}
+
 // Buffer is a simple in-memory buffer that can be used as an io.Reader or io.Writer.
 // It's like bytes.Buffer, but it reads and writes to a channel instead of a byte slice.
 // So the read and write operations can block until data is available.
 // One of the NewBuffer* functions should be used to create a new buffer.
 // The Close method should be called to notify readers that no more data will be written.
+//
+// The written data is copied to a chunk from a pool, which is put back once it's read, so writing
+// doesn't allocate in the steady state. The caller can reuse the slice which is passed to Write.
+//
+// The data in the channel can't be inspected, so a buffer created with NewBufferRetain also keeps
+// a copy of the unread data, which the Bytes method returns without consuming it.
 type Buffer struct {
-	ch  chan string
-	str string
-	pos int
+	ch     chan string  // the channel of NewBufferChan, nil otherwise
+	chunks chan *[]byte // the copies of the written data, if ch is nil
+	str    string
+	chunk  *[]byte
+	pos    int
+
+	wmu    sync.Mutex // keeps the order of the written data the same as the order in the channel
+	mu     sync.Mutex // guards the fields below
+	unread int
+	retain bool
+	data   []byte // the unread data, if retain is true
 }
 
 // Read reads data from the channel and returns it in p. It will block until data
 // is available or the channel is closed.
 func (b *Buffer) Read(p []byte) (n int, err error) {
-	if b.pos >= len(b.str) {
-		str, ok := <-b.ch
-		if !ok {
-			return 0, io.EOF
+	if b.ch != nil {
+		if b.pos >= len(b.str) {
+			str, ok := <-b.ch
+			if !ok {
+				return 0, io.EOF
+			}
+			b.str = str
+			b.pos = 0
+		}
+
+		// Copy data from current string to p
+		n = copy(p, b.str[b.pos:])
+	} else {
+		if b.chunk == nil || b.pos >= len(*b.chunk) {
+			if b.chunk != nil {
+				putChunk(b.chunk)
+			}
+			chunk, ok := <-b.chunks
+			if !ok {
+				b.chunk = nil
+				return 0, io.EOF
+			}
+			b.chunk = chunk
+			b.pos = 0
 		}
-		b.str = str
-		b.pos = 0
-	}
 
-	// Copy data from current string to p
-	n = copy(p, b.str[b.pos:])
+		// Copy data from current chunk to p
+		n = copy(p, (*b.chunk)[b.pos:])
+	}
 	b.pos += n
+
+	b.mu.Lock()
+	b.unread = max(b.unread-n, 0)
+	if b.retain {
+		b.data = b.data[min(n, len(b.data)):]
+	}
+	b.mu.Unlock()
 	return n, nil
 }
 
 func (b *Buffer) Write(p []byte) (n int, err error) {
-	return b.WriteString(string(p))
+	return write(b, p)
 }
 
 func (b *Buffer) Close() error {
-	close(b.ch)
+	if b.ch != nil {
+		close(b.ch)
+	} else {
+		close(b.chunks)
+	}
 	return nil
 }
 
 func (b *Buffer) WriteString(s string) (n int, err error) {
-	b.ch <- s
-	return len(s), nil
+	return write(b, s)
+}
+
+// write sends a copy of the data to the channel.
+func write[T string | []byte](b *Buffer, data T) (n int, err error) {
+	b.wmu.Lock()
+	defer b.wmu.Unlock()
+
+	b.mu.Lock()
+	b.unread += len(data)
+	if b.retain {
+		b.data = append(b.data, data...)
+	}
+	b.mu.Unlock()
+
+	if b.ch != nil {
+		b.ch <- string(data)
+	} else {
+		chunk := chunkPool.Get().(*[]byte)
+		*chunk = append((*chunk)[:0], data...)
+		b.chunks <- chunk
+	}
+	return len(data), nil
+}
+
+// putChunk puts a chunk which has been read back to the pool.
+func putChunk(chunk *[]byte) {
+	if cap(*chunk) <= maxPooledChunk {
+		chunkPool.Put(chunk)
+	}
+}
+
+// Len returns the number of bytes which are written but not read yet.
+// The data sent to the channel of NewBufferChan directly is not counted.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.unread
+}
+
+// Bytes returns a copy of the unread data without consuming it, like bytes.Buffer does.
+// It returns nil if the buffer is not created with NewBufferRetain.
+func (b *Buffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.retain {
+		return nil
+	}
+	return append([]byte{}, b.data...)
 }
 
+// String returns the unread data as a string. It doesn't consume the data if the buffer is created
+// with NewBufferRetain, otherwise it reads the data until the buffer is closed.
 func (b *Buffer) String() string {
 	if b == nil {
 		return "<nil>"
 	}
+	if b.retain {
+		return string(b.Bytes())
+	}
 	bytes, err := io.ReadAll(b)
 	if err != nil {
 		return ""
@@ -59,14 +169,12 @@ func (b *Buffer) String() string {
 }
 
 func NewBuffer() *Buffer {
-	return &Buffer{
-		ch: make(chan string, bufferSize),
-	}
+	return NewBufferSize(bufferSize)
 }
 
 func NewBufferString(s string) *Buffer {
 	b := NewBuffer()
-	b.ch <- s
+	b.WriteString(s)
 	return b
 }
 
@@ -76,8 +184,15 @@ func NewBufferChan(ch chan string) *Buffer {
 	}
 }
 
+// NewBufferRetain creates a buffer which keeps a copy of the unread data, see the Bytes method.
+func NewBufferRetain() *Buffer {
+	b := NewBuffer()
+	b.retain = true
+	return b
+}
+
 func NewBufferSize(size int) *Buffer {
 	return &Buffer{
-		ch: make(chan string, size),
+		chunks: make(chan *[]byte, size),
 	}
 }
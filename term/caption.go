@@ -0,0 +1,38 @@
+package term
+
+import (
+	"fmt"
+	"html"
+	"sync/atomic"
+)
+
+// figureCount is the number of the last figure created by Captioned.
+var figureCount atomic.Int32
+
+type captioned struct {
+	e       BlockElement
+	number  int
+	caption string
+}
+
+// Captioned returns a figure which shows the given block element with a caption beneath it, such as
+// "Figure 1: Sales by month". The figures are numbered in the order of the Captioned calls.
+func Captioned(e BlockElement, caption string) BlockElement {
+	return &captioned{e: e, number: int(figureCount.Add(1)), caption: caption}
+}
+
+func (c *captioned) HTML() string {
+	// The element keeps its own options in a box, like a cell of a layout
+	var conf blockConfig
+	for _, op := range elementOptions(c.e) {
+		op(&conf)
+	}
+	return fmt.Sprintf("<figure class='goterm-figure'>%s<figcaption class='goterm-caption'>Figure %d: %s</figcaption></figure>",
+		boxHTML(c.e.HTML(), 0, 0, &conf), c.number, html.EscapeString(c.caption))
+}
+
+// Options makes the figure take up the full width of the row, so that an element which fills its box,
+// like an iframe, still has the full width. The element and the caption are centered in the figure.
+func (c *captioned) Options() []BlockOption {
+	return []BlockOption{fillOption}
+}
@@ -0,0 +1,72 @@
+package term
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// eventQueueSize is the number of events which can wait for a slow client, more events are dropped.
+const eventQueueSize = 1024
+
+// event is a message for the scripts of the page, which is not part of the output.
+type event struct {
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data"`
+}
+
+// eventHub sends the events to the current subscribers. Unlike the hub of the output, it doesn't keep
+// the events, so a subscriber only receives the events which are sent after it subscribes.
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[chan event]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[chan event]struct{})}
+}
+
+// subscribe returns a channel of the events, and a function which unsubscribes and closes the channel.
+func (h *eventHub) subscribe() (<-chan event, func()) {
+	ch := make(chan event, eventQueueSize)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs, ch)
+		close(ch)
+		h.mu.Unlock()
+	}
+}
+
+// publish sends the event to every subscriber without blocking.
+func (h *eventHub) publish(e event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// SendEvent sends the data as JSON to the pages which receive the output with the EnableSSE option.
+// The page dispatches it as a CustomEvent named "goterm:" + name on the window, whose detail is the data,
+// after the output received before it is shown.
+//
+// An event is not part of the output: it's not cached or saved, and a page only receives the events which
+// are sent while it's connected. An event is dropped for a page which falls too far behind.
+func (t *Term) SendEvent(name string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	t.events.publish(event{Name: name, Data: b})
+	return nil
+}
+
+// SendEvent sends an event to the pages of the terminal, see Term.SendEvent.
+func SendEvent(name string, data any) error {
+	return term.SendEvent(name, data)
+}
@@ -1,7 +1,9 @@
 package term
 
 import (
+	"io"
 	"iter"
+	"net/http"
 	"os"
 )
 
@@ -11,11 +13,26 @@ var sysStderr = os.Stderr
 var term = NewTerm()
 
 // Open opens the terminal. This function should be called at the beginning of the program.
-func Open(options ...TermOption) {
+// It returns ErrOpened if the terminal is already opened.
+func Open(options ...TermOption) error {
+	renew()
+	return term.Open(options...)
+}
+
+// MustOpen is like Open but panics if the terminal can not be opened.
+func MustOpen(options ...TermOption) {
+	renew()
+	term.MustOpen(options...)
+}
+
+// renew replaces a closed default terminal with a new one, which keeps the hooks registered by
+// the OnBlock and OnLine functions.
+func renew() {
 	if term.closed {
+		hooks := term.hooks
 		term = NewTerm()
+		term.hooks = hooks
 	}
-	term.Open(options...)
 }
 
 // Close closes the terminal. This function should be called at the end of the program.
@@ -23,9 +40,42 @@ func Close() {
 	term.Close()
 }
 
+// TryClose is like Close but returns ErrNotOpened instead of panicking if the terminal is not opened or already closed.
+func TryClose() error {
+	return term.TryClose()
+}
+
 // HTML returns a sequence of strings for the HTML content.
 // If page is true, the HTML content is a full page. Otherwise, it is a fragment.
 // One should only call this function when the format option is set to Custom.
 func HTML(page bool) iter.Seq[string] {
 	return term.HTML(page)
 }
+
+// TryHTML is like HTML but returns ErrNotCustom instead of panicking if the format is not Custom.
+func TryHTML(page bool) (iter.Seq[string], error) {
+	return term.TryHTML(page)
+}
+
+// JSONL returns a sequence of JSON lines, one for each captured text line and each HTML block.
+// One should only call this function when the format option is set to Custom.
+func JSONL() iter.Seq[string] {
+	return term.JSONL()
+}
+
+// Handler returns the handler of the web server, without listening on a port.
+// One should only call this function when the format option is set to Custom.
+func Handler() http.Handler {
+	return term.Handler()
+}
+
+// Stdin returns a reader of the text submitted from the browser.
+// One should only use this function when the EnableInput option is set.
+func Stdin() io.Reader {
+	return term.Stdin()
+}
+
+// Reader returns a reader of the captured text, without the tags of the escaped HTML content.
+func Reader() io.ReadCloser {
+	return term.Reader()
+}
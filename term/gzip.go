@@ -0,0 +1,64 @@
+package term
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// gzipResponse writes a response which is compressed with gzip if the client accepts it.
+// Flush flushes the compressed data as well, so that the response can still be streamed.
+type gzipResponse struct {
+	io.Writer
+	w  http.ResponseWriter
+	gz *gzip.Writer
+}
+
+// newGzipResponse sets the encoding headers, so it must be called before writing the response.
+func newGzipResponse(w http.ResponseWriter, r *http.Request) *gzipResponse {
+	w.Header().Add("Vary", "Accept-Encoding")
+	if !acceptsGzip(r) {
+		return &gzipResponse{Writer: w, w: w}
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+	gz := gzip.NewWriter(w)
+	return &gzipResponse{Writer: gz, w: w, gz: gz}
+}
+
+func (g *gzipResponse) Flush() {
+	if g.gz != nil {
+		g.gz.Flush()
+	}
+	if flusher, ok := g.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close writes the end of the compressed data, it doesn't close the response.
+func (g *gzipResponse) Close() error {
+	if g.gz != nil {
+		return g.gz.Close()
+	}
+	return nil
+}
+
+// acceptsGzip tells whether the Accept-Encoding header of the request allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.TrimSpace(coding)
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		// A zero quality value means not acceptable
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package term
+
+import (
+	"slices"
+	"sync"
+)
+
+// hook is a registered function, the pointer identifies it for removal.
+type hook struct {
+	fn func(string)
+}
+
+// hookSet holds the functions registered by OnBlock and OnLine for a terminal.
+type hookSet struct {
+	mu     sync.RWMutex
+	blocks []*hook
+	lines  []*hook
+}
+
+// OnBlock registers a function which is called with the HTML of every block printed by the Block functions
+// to the default terminal, see the OnBlock method.
+func OnBlock(fn func(html string)) (remove func()) {
+	return term.OnBlock(fn)
+}
+
+// OnLine registers a function which is called with every text line captured by the default terminal,
+// see the OnLine method.
+func OnLine(fn func(line string)) (remove func()) {
+	return term.OnLine(fn)
+}
+
+// OnBlock registers a function which is called with the HTML of every block printed by the Block functions,
+// such as a chart or a table, e.g. to collect the charts for an index. The HTML includes the row and the box
+// of the block. The function is called synchronously before the block is printed, so it should be fast.
+// It returns a function which removes the hook.
+func (t *Term) OnBlock(fn func(html string)) (remove func()) {
+	return t.hooks.add(&t.hooks.blocks, fn)
+}
+
+// OnLine registers a function which is called with every captured text line, after the LineFilter option.
+// The lines of HTML blocks are not included. The function is called by the goroutine which reads the
+// captured output, so a slow function delays the output.
+// It returns a function which removes the hook.
+func (t *Term) OnLine(fn func(line string)) (remove func()) {
+	return t.hooks.add(&t.hooks.lines, fn)
+}
+
+// BlockHook registers fn when the terminal is opened, like the OnBlock method.
+func BlockHook(fn func(html string)) func(t *Term) {
+	return func(t *Term) {
+		t.OnBlock(fn)
+	}
+}
+
+// LineHook registers fn when the terminal is opened, like the OnLine method.
+func LineHook(fn func(line string)) func(t *Term) {
+	return func(t *Term) {
+		t.OnLine(fn)
+	}
+}
+
+func (h *hookSet) add(list *[]*hook, fn func(string)) func() {
+	hk := &hook{fn: fn}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*list = append(*list, hk)
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		*list = slices.DeleteFunc(*list, func(x *hook) bool { return x == hk })
+	}
+}
+
+// run calls the registered functions of the list with the value.
+func (h *hookSet) run(list *[]*hook, v string) {
+	h.mu.RLock()
+	fns := slices.Clone(*list)
+	h.mu.RUnlock()
+
+	for _, hk := range fns {
+		hk.fn(v)
+	}
+}
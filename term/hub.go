@@ -0,0 +1,127 @@
+package term
+
+import (
+	"iter"
+	"slices"
+	"sync"
+	"time"
+)
+
+// record is a captured line, with the stream which it comes from and the time when it's captured.
+type record struct {
+	text   string
+	stream string // "stdout" or "stderr"
+	time   time.Time
+}
+
+// hub fans out the captured lines to multiple subscribers.
+// Each subscriber receives every line from the oldest available one, followed by the live tail.
+//
+// If retain is true, all lines are kept so that a late subscriber can replay the whole output.
+// Otherwise, the lines which have been received by all current subscribers are dropped,
+// and a late subscriber starts from the oldest line that is still available.
+type hub struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	lines  []record
+	offset int  // the index of lines[0] in the whole output
+	closed bool // no more lines will be pushed
+	retain bool
+
+	// positions of the current subscribers, used to drop lines when not retaining
+	cursors map[*int]struct{}
+}
+
+func newHub() *hub {
+	h := &hub{cursors: make(map[*int]struct{})}
+	h.cond = sync.NewCond(&h.mu)
+	return h
+}
+
+// push appends the lines and wakes up the subscribers. The lines are received by the subscribers together,
+// without the lines pushed by another goroutine in between.
+func (h *hub) push(lines ...record) {
+	h.mu.Lock()
+	h.lines = append(h.lines, lines...)
+	h.mu.Unlock()
+	h.cond.Broadcast()
+}
+
+// close marks the end of the output. Subscribers return after receiving the remaining lines.
+func (h *hub) close() {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+	h.cond.Broadcast()
+}
+
+// len returns the number of lines pushed so far, including the dropped ones.
+func (h *hub) len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.offset + len(h.lines)
+}
+
+// snapshot returns a sequence of the lines which are available now, it doesn't wait for new lines.
+func (h *hub) snapshot() iter.Seq[record] {
+	h.mu.Lock()
+	// The pushed lines are never modified, so the slice can be shared
+	lines := h.lines
+	h.mu.Unlock()
+	return slices.Values(lines)
+}
+
+// subscribe returns a sequence of all available lines, which blocks for new lines until the hub is closed.
+func (h *hub) subscribe() iter.Seq[record] {
+	return func(yield func(record) bool) {
+		h.mu.Lock()
+		pos := h.offset
+		h.cursors[&pos] = struct{}{}
+		defer func() {
+			h.mu.Lock()
+			delete(h.cursors, &pos)
+			h.trim()
+			h.mu.Unlock()
+		}()
+
+		for {
+			// Wait for new lines
+			for pos >= h.offset+len(h.lines) && !h.closed {
+				h.cond.Wait()
+			}
+			if pos >= h.offset+len(h.lines) {
+				h.mu.Unlock()
+				return
+			}
+
+			// Take a batch of lines, and yield them without holding the lock
+			batch := h.lines[pos-h.offset:]
+			pos += len(batch)
+			h.trim()
+			h.mu.Unlock()
+
+			for _, line := range batch {
+				if !yield(line) {
+					return
+				}
+			}
+			h.mu.Lock()
+		}
+	}
+}
+
+// trim drops the lines which have been received by all subscribers. The caller must hold the lock.
+func (h *hub) trim() {
+	if h.retain || len(h.cursors) == 0 {
+		return
+	}
+	low := h.offset + len(h.lines)
+	for pos := range h.cursors {
+		low = min(low, *pos)
+	}
+	if n := low - h.offset; n > 0 {
+		// The batches taken by subscribers still refer to the dropped lines, so don't clear them
+		h.lines = h.lines[n:]
+		h.offset = low
+	}
+}
@@ -0,0 +1,41 @@
+package term
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Stdin returns a reader of the text submitted from the browser.
+// Each submitted message becomes a line. The reader returns io.EOF after the terminal is closed.
+// Input is only available when the EnableInput option is used with a web server format.
+func (t *Term) Stdin() io.Reader {
+	return t.stdinReader
+}
+
+// serveInput receives text messages from the browser over a WebSocket and writes them to Stdin.
+func (t *Term) serveInput(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	for {
+		msg, err := ws.ReadMessage()
+		if err != nil {
+			if err != io.EOF {
+				t.logger.Printf("websocket read failed: %v", err)
+			}
+			return
+		}
+
+		// Write the message as a line, this will block until it is read or the terminal is closed
+		// Normalize the line endings, since a textarea may submit CRLF
+		text := strings.ReplaceAll(string(msg), "\r\n", "\n")
+		line := strings.TrimSuffix(text, "\n") + "\n"
+		if _, err := io.WriteString(t.stdinWriter, line); err != nil {
+			return
+		}
+	}
+}
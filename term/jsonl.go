@@ -0,0 +1,64 @@
+package term
+
+import (
+	"encoding/json"
+	"iter"
+	"strings"
+	"time"
+)
+
+// jsonRecord is a line of the JSONL output.
+type jsonRecord struct {
+	Type   string    `json:"type"` // "text" or "html"
+	Data   string    `json:"data"`
+	Stream string    `json:"stream"` // "stdout" or "stderr"
+	TS     time.Time `json:"ts"`
+}
+
+// JSONL returns a sequence of JSON lines for machine consumption, one for each captured text line and each HTML block:
+//
+//	{"type":"text","data":"hello","stream":"stdout","ts":"2024-01-02T15:04:05.999999999Z"}
+//
+// The data of an HTML block is its content without the tags, and the time of a block is when it starts.
+// It panics if the format is not Custom, use the JSONLines format to print the lines to stdout instead.
+func (t *Term) JSONL() iter.Seq[string] {
+	if t.format != Custom {
+		panic(ErrNotCustom)
+	}
+	return t.jsonl()
+}
+
+func (t *Term) jsonl() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		t.chReaderWg.Add(1)
+		defer t.chReaderWg.Done()
+
+		var emit = func(r jsonRecord) bool {
+			b, _ := json.Marshal(r)
+			return yield(string(b) + "\n")
+		}
+
+		var block *jsonRecord
+		var html []string
+		for r := range t.hub.subscribe() {
+			isTag := strings.TrimRight(r.text, " \t\r") == HtmlTag
+			switch {
+			case block != nil && isTag:
+				// The end of an HTML block
+				block.Data = strings.Join(html, "\n")
+				if !emit(*block) {
+					return
+				}
+				block, html = nil, nil
+			case block != nil:
+				html = append(html, r.text)
+			case isTag:
+				block = &jsonRecord{Type: "html", Stream: r.stream, TS: r.time}
+			default:
+				if !emit(jsonRecord{Type: "text", Data: r.text, Stream: r.stream, TS: r.time}) {
+					return
+				}
+			}
+		}
+	}
+}
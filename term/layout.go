@@ -0,0 +1,103 @@
+package term
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// layout is a BlockElement which places multiple block elements in one row.
+// Each child is wrapped in its own box, so it keeps its own size and options.
+type layout struct {
+	cols   int // 0 means a flex row, otherwise a grid with the given number of columns
+	blocks []BlockElement
+}
+
+// Row lays the given block elements side by side in a single row.
+// The elements wrap to the next line when there is not enough space.
+func Row(blocks ...BlockElement) BlockElement {
+	return &layout{blocks: blocks}
+}
+
+// Grid lays the given block elements in a grid with the given number of columns.
+func Grid(cols int, blocks ...BlockElement) BlockElement {
+	if cols <= 0 {
+		cols = 1
+	}
+	return &layout{cols: cols, blocks: blocks}
+}
+
+// Options makes the layout take up the full width of the row.
+func (l *layout) Options() []BlockOption {
+	return []BlockOption{fillOption}
+}
+
+func (l *layout) HTML() string {
+	var buf bytes.Buffer
+	if l.cols > 0 {
+		fmt.Fprintf(&buf, "<div class='goterm-grid' style='grid-template-columns: repeat(%d, minmax(0, 1fr));'>", l.cols)
+	} else {
+		buf.WriteString("<div class='goterm-flex'>")
+	}
+	for _, e := range l.blocks {
+		var conf blockConfig
+		for _, op := range elementOptions(e) {
+			op(&conf)
+		}
+		buf.WriteString("<div class='goterm-cell'>")
+		buf.WriteString(boxHTML(e.HTML(), 0, 0, &conf))
+		buf.WriteString("</div>")
+	}
+	buf.WriteString("</div>")
+	return buf.String()
+}
+
+type hr struct{}
+
+// HR returns a horizontal rule which separates the blocks before and after it.
+func HR() BlockElement {
+	return hr{}
+}
+
+func (hr) HTML() string {
+	return "<hr class='goterm-hr'>"
+}
+
+func (hr) Options() []BlockOption {
+	return []BlockOption{fillOption}
+}
+
+type spacer int
+
+// Spacer returns an empty block with the given height in pixels, which adds vertical whitespace between blocks.
+func Spacer(heightPx int) BlockElement {
+	return spacer(heightPx)
+}
+
+func (s spacer) HTML() string {
+	return "<div class='goterm-spacer'></div>"
+}
+
+func (s spacer) Options() []BlockOption {
+	return []BlockOption{fillOption, SizeOption(0, int(s))}
+}
+
+type heading struct {
+	level int
+	text  string
+}
+
+// Heading returns a section title with the given level (1 to 6).
+// Unlike the captured output, headings use a light, document-style look.
+func Heading(level int, text string) BlockElement {
+	level = min(max(level, 1), 6)
+	return &heading{level: level, text: text}
+}
+
+func (h *heading) HTML() string {
+	return fmt.Sprintf("<h%d class='goterm-heading'>%s</h%[1]d>", h.level, html.EscapeString(h.text))
+}
+
+func (h *heading) Options() []BlockOption {
+	return []BlockOption{fillOption}
+}
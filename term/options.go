@@ -1,5 +1,11 @@
 package term
 
+import (
+	"fmt"
+	"html"
+	"io"
+)
+
 type OutputFormat int
 
 const (
@@ -8,17 +14,34 @@ const (
 	HTMLContent                     // Print HTML content
 	Raw                             // Print raw text, useful for debugging
 	Custom                          // Print nothing, user is expected to call the HTML function
+	JSONLines                       // Print JSON lines, see the JSONL function
 )
 
 type TermOption func(*Term)
 
 // Detach from the stdout/stderr of the current process.
+// A detached terminal doesn't capture anything, the content is added with the Print and PrintHTMLBlock methods.
 func Detach() func(t *Term) {
 	return func(t *Term) {
 		t.attachOutput = false
 	}
 }
 
+// CaptureStdout sets whether the terminal captures stdout. The default is true.
+// An uncaptured stream is left untouched, e.g. to keep it clean for piping to another program.
+func CaptureStdout(capture bool) func(t *Term) {
+	return func(t *Term) {
+		t.captureStdout = capture
+	}
+}
+
+// CaptureStderr sets whether the terminal captures stderr and the output of the log package. The default is true.
+func CaptureStderr(capture bool) func(t *Term) {
+	return func(t *Term) {
+		t.captureStderr = capture
+	}
+}
+
 // Format sets the format of the terminal output.
 // The default is FormatRaw.
 func Format(format OutputFormat) func(t *Term) {
@@ -27,7 +50,33 @@ func Format(format OutputFormat) func(t *Term) {
 	}
 }
 
+// OutputTo writes the output of the HTMLPage, HTMLContent and JSONLines formats to w instead of stdout,
+// e.g. a file or a network connection. All the output is written before the Close method returns.
+func OutputTo(w io.Writer) func(t *Term) {
+	return func(t *Term) {
+		t.output = w
+	}
+}
+
+// TeeFile mirrors the captured output to the file at path, which is created or truncated when the terminal is opened.
+// The file gets the raw text, including the escaped HTML content. It works with every format.
+func TeeFile(path string) func(t *Term) {
+	return func(t *Term) {
+		t.teePath = path
+	}
+}
+
+// RawWithFile shows the output on the console as the Raw format does, and saves it to the file at path.
+// It's a shortcut for Format(Raw) and TeeFile(path).
+func RawWithFile(path string) func(t *Term) {
+	return func(t *Term) {
+		t.format = Raw
+		t.teePath = path
+	}
+}
+
 // BindPort will start a web server to serve the terminal output on the specified port.
+// Open returns an error if the port is not available, see the FallbackRandomPort option.
 func BindPort(port int) func(t *Term) {
 	return func(t *Term) {
 		t.format = Custom
@@ -35,3 +84,110 @@ func BindPort(port int) func(t *Term) {
 		t.cacheOutput = true
 	}
 }
+
+// FallbackRandomPort makes the web server of the BindPort option listen on a random port if the port
+// is not available, such as being in use, instead of failing to open the terminal. The URL is printed as usual.
+func FallbackRandomPort() func(t *Term) {
+	return func(t *Term) {
+		t.portFallback = true
+	}
+}
+
+// BindHost sets the host, such as an interface address, which the web server listens on, instead of
+// localhost for the HTMLWindow format and all interfaces for the BindPort option. It's also the host of the printed URL.
+// A host other than a loopback address exposes the output, and the input if enabled, to the network.
+func BindHost(host string) func(t *Term) {
+	return func(t *Term) {
+		t.bindHost = host
+	}
+}
+
+// EnableSSE makes the web server push the terminal output to the browser with server-sent events,
+// instead of a single streaming HTTP response. It is more robust behind proxies and allows reconnection:
+// a page which loses its connection resumes from the last line it received.
+func EnableSSE() func(t *Term) {
+	return func(t *Term) {
+		t.sse = true
+	}
+}
+
+// EnableInput adds an input box to the served page. The submitted text is sent back to the program
+// over a WebSocket and can be read from the Stdin method line by line.
+func EnableInput() func(t *Term) {
+	return func(t *Term) {
+		t.input = true
+	}
+}
+
+// LineFilter transforms each captured line with fn before it's cached or rendered, e.g. to mask secrets.
+// It applies to the text lines and the lines of HTML content, but not to the tags of the HTML content.
+// If fn returns DropLine, the line is dropped entirely.
+func LineFilter(fn func(line string) string) func(t *Term) {
+	return func(t *Term) {
+		t.lineFilter = fn
+	}
+}
+
+// ThrottleLines limits how often the served page is updated to perSec times per second.
+// The output of a burst is sent to the browser in batches, which keeps the page responsive under heavy logging.
+func ThrottleLines(perSec int) func(t *Term) {
+	return func(t *Term) {
+		t.throttleLines = perSec
+	}
+}
+
+// MaxLineLength splits the output lines which are longer than n bytes into chunks of at most n bytes,
+// as if soft line breaks were inserted. It bounds the memory used by a huge line without a newline.
+// The default is MaxBuffersize.
+func MaxLineLength(n int) func(t *Term) {
+	return func(t *Term) {
+		t.maxLineLength = n
+	}
+}
+
+// MaxWidth limits the width of the page content to px pixels and centers it, which makes a report
+// easier to read on a wide window. The default is to use the full width.
+func MaxWidth(px int) func(t *Term) {
+	return func(t *Term) {
+		t.maxWidth = px
+	}
+}
+
+// EnableDownload adds a download button to the served page, which saves a snapshot of the output
+// as a self-contained HTML file. The snapshot is also available at the "/download" path of the web server.
+// The whole output is kept in memory for the snapshot, like with caching.
+func EnableDownload() func(t *Term) {
+	return func(t *Term) {
+		t.download = true
+	}
+}
+
+// NoAutoScroll leaves out the script which scrolls the page to the bottom as the output grows.
+// It suits a static report, which is read from the top.
+func NoAutoScroll() func(t *Term) {
+	return func(t *Term) {
+		t.noAutoScroll = true
+	}
+}
+
+// HeadHTML adds the HTML to the head of the page, such as a script or a stylesheet which the HTML blocks need.
+// The HTML is added as it is, so it must be trusted.
+func HeadHTML(h string) func(t *Term) {
+	return func(t *Term) {
+		t.headHTML = append(t.headHTML, h)
+	}
+}
+
+// AddScript adds a script element which loads the script at url to the head of the page, see HeadHTML.
+func AddScript(url string) func(t *Term) {
+	return HeadHTML(fmt.Sprintf("<script src=\"%s\"></script>", html.EscapeString(url)))
+}
+
+// PrintMode adds print styles to the page, so that printing it, or saving it as a PDF in the browser,
+// makes a clean document: the text is dark on a light background, the blocks don't break across pages,
+// and the input form and the download button are hidden.
+func PrintMode() func(t *Term) {
+	return func(t *Term) {
+		t.printMode = true
+	}
+}
@@ -0,0 +1,26 @@
+package term
+
+import (
+	"io"
+)
+
+// Reader returns a reader of the captured text, which can be used while the output is being captured.
+// The text is what the program printed, with the HtmlTag lines of the escaped HTML content removed.
+// The reader returns io.EOF after the terminal is closed. Close the reader if it's not read to the end,
+// so that the captured lines are not held for it.
+func (t *Term) Reader() io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		for kind, line := range classify(t.lines()) {
+			if kind == tagLine {
+				continue
+			}
+			if _, err := io.WriteString(pw, line+"\n"); err != nil {
+				// The reader is closed
+				return
+			}
+		}
+		pw.Close()
+	}()
+	return pr
+}
@@ -0,0 +1,35 @@
+package term
+
+import (
+	"fmt"
+	"html"
+)
+
+// Section groups everything printed to stdout inside fn under a titled section of the default terminal.
+// Sections can be nested, and a nested section is indented in its parent.
+// The output of stderr is not ordered with the output of stdout, so it may appear outside the section.
+func Section(name string, fn func()) {
+	term.section(name, fn, PrintHtml)
+}
+
+// Section groups the content added by the Print methods inside fn under a titled section, like the
+// Section function does with stdout.
+func (t *Term) Section(name string, fn func()) {
+	t.section(name, fn, func(html string) {
+		t.Print(escapeHtml(html) + "\n")
+	})
+}
+
+// section prints the opening and the closing html blocks of a section around fn. The opening block is
+// marked with the data-goterm-open attribute, so that the page of the SSE option, which receives each
+// block as a whole element, puts the output up to the closing block into the section.
+func (t *Term) section(name string, fn func(), print func(html string)) {
+	depth := t.sectionDepth.Add(1)
+	defer t.sectionDepth.Add(-1)
+
+	// The title level follows the nesting, from h2 to h6
+	level := min(int(depth)+1, 6)
+	print(fmt.Sprintf("<section class='goterm-section' data-goterm-open><h%d class='goterm-heading'>%s</h%[1]d>", level, html.EscapeString(name)))
+	defer print("</section>")
+	fn()
+}
@@ -0,0 +1,85 @@
+package term
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// Default size and color of a sparkline
+const (
+	sparklineWidth  = 120
+	sparklineHeight = 32
+)
+
+var sparklineColor = color.RGBA{R: 0x54, G: 0x70, B: 0xc6, A: 0xff}
+
+type sparkline struct {
+	values []float64
+	ops    []BlockOption
+}
+
+// Sparkline returns a tiny line chart of the values without axes, which shows a trend at a glance.
+// It's drawn as a small SVG of 120x32 pixels in a blue color by default, use SizeOption and ColorOption to change them.
+// NaN values leave gaps in the line.
+func Sparkline(values []float64, ops ...BlockOption) BlockElement {
+	return &sparkline{values: values, ops: ops}
+}
+
+func (s *sparkline) HTML() string {
+	// Stretch the view box to the size of the box, and keep the stroke width
+	var buf bytes.Buffer
+	buf.WriteString(`<svg class="goterm-sparkline" width="100%" height="100%" viewBox="0 0 100 100" preserveAspectRatio="none">`)
+	for _, points := range s.segments() {
+		fmt.Fprintf(&buf, `<polyline points="%s" fill="none" stroke="currentColor" stroke-width="1.5" vector-effect="non-scaling-stroke"/>`, points)
+	}
+	buf.WriteString(`</svg>`)
+	return buf.String()
+}
+
+func (s *sparkline) Options() []BlockOption {
+	return append([]BlockOption{SizeOption(sparklineWidth, sparklineHeight), ColorOption(sparklineColor)}, s.ops...)
+}
+
+// segments returns the points of the polylines in the 100x100 view box, which are separated by NaN values.
+func (s *sparkline) segments() []string {
+	valid := slices.DeleteFunc(slices.Clone(s.values), func(v float64) bool { return math.IsNaN(v) || math.IsInf(v, 0) })
+	if len(valid) == 0 {
+		return nil
+	}
+	low, high := slices.Min(valid), slices.Max(valid)
+
+	var segments []string
+	var points bytes.Buffer
+	for i, v := range s.values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			if points.Len() > 0 {
+				segments = append(segments, points.String())
+				points.Reset()
+			}
+			continue
+		}
+
+		// A constant series is drawn in the middle
+		y := 50.0
+		if high > low {
+			// Leave a margin for the stroke
+			y = 95 - (v-low)/(high-low)*90
+		}
+		if len(s.values) == 1 {
+			// A single value is drawn as a flat line
+			fmt.Fprintf(&points, "0,%.2f 100,%.2f", y, y)
+			continue
+		}
+		if points.Len() > 0 {
+			points.WriteByte(' ')
+		}
+		fmt.Fprintf(&points, "%.2f,%.2f", float64(i)/float64(len(s.values)-1)*100, y)
+	}
+	if points.Len() > 0 {
+		segments = append(segments, points.String())
+	}
+	return segments
+}
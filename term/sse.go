@@ -0,0 +1,113 @@
+package term
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// serveSSEPage serves a page shell which receives the terminal output from the events endpoint.
+func (t *Term) serveSSEPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	fmt.Fprint(w, t.getHtmlPagePrefix())
+	fmt.Fprint(w, "<div id=\"goterm-main\"></div>\n")
+	fmt.Fprint(w, SSEScript)
+	fmt.Fprint(w, t.getHtmlPageSuffix())
+}
+
+// serveSSEEvents pushes the terminal output as server-sent events.
+// Each text line is sent as a "text" event, and each HTML block is sent as a whole in an "html" event.
+// The event id is the number of lines consumed so far, so a reconnecting client which sends
+// the Last-Event-ID header will resume from where it left off.
+// The events of SendEvent are sent as "custom" events without an id, so they don't change where it resumes.
+// It returns true if the whole output has been sent.
+func (t *Term) serveSSEEvents(w http.ResponseWriter, r *http.Request) bool {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		return false
+	}
+
+	// Skip the lines which the client has already received
+	skip := t.cursor(r)
+	if id, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil {
+		skip = id
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Disable response buffering of nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Events are not compressed, the response is only used for throttling
+	out := t.throttle(&gzipResponse{Writer: w, w: w})
+	defer out.Close()
+
+	// The output and the custom events are sent by different goroutines
+	var mu sync.Mutex
+	var send = func(event string, id int, data string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Context().Err() != nil {
+			return false
+		}
+		b, _ := json.Marshal(data)
+		fmt.Fprintf(out, "id: %d\nevent: %s\ndata: %s\n\n", id, event, b)
+		out.Flush()
+		return true
+	}
+
+	events, unsubscribe := t.events.subscribe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range events {
+			b, _ := json.Marshal(e)
+			mu.Lock()
+			fmt.Fprintf(out, "event: custom\ndata: %s\n\n", b)
+			out.Flush()
+			mu.Unlock()
+		}
+	}()
+	defer func() {
+		unsubscribe()
+		<-done
+	}()
+
+	var block []string
+	var count int
+	for kind, line := range classify(t.lines()) {
+		count++
+		if count <= skip {
+			if kind == htmlLine {
+				block = append(block, line)
+			} else {
+				block = nil
+			}
+			continue
+		}
+		switch kind {
+		case tagLine:
+			// The end of an html block
+			if block != nil {
+				if !send("html", count, strings.Join(block, "\n")) {
+					return false
+				}
+				block = nil
+			}
+		case htmlLine:
+			block = append(block, line)
+		case textLine:
+			if !send("text", count, line) {
+				return false
+			}
+		}
+	}
+	return send("end", count, "")
+}
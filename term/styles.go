@@ -26,18 +26,51 @@ iframe {
 	border: none;
 }
 iframe.echart {
-    /* set a minimum width for echart */
-    min-width: 916px;
+    /* fit the container, but keep the chart readable on a narrow window */
+    width: 100%;
+    min-width: 320px;
 }
 `
 
+// IframeHeightScript is added to the page of an iframe, to post the height of its content to the parent page.
+const IframeHeightScript = `<script>
+(function() {
+    function post() {
+        parent.postMessage({gotermHeight: document.documentElement.offsetHeight}, '*');
+    }
+    window.addEventListener('load', post);
+    if (typeof ResizeObserver !== 'undefined') {
+        new ResizeObserver(post).observe(document.documentElement);
+    }
+})();
+</script>
+`
+
+// IframeResizeScript fits the height of an iframe to the height posted by its content, see IframeHeightScript.
+// An iframe in a box with a fixed height keeps the height of the box.
+const IframeResizeScript = `
+<script>
+window.addEventListener('message', function(event) {
+    const height = event.data && event.data.gotermHeight;
+    if (typeof height !== 'number') {
+        return;
+    }
+    document.querySelectorAll('iframe').forEach(function(iframe) {
+        if (iframe.contentWindow === event.source && !iframe.parentElement.style.height) {
+            iframe.style.height = height + 'px';
+        }
+    });
+});
+</script>
+`
+
 // Block div for html content such as charts and plots.
 // For x-axis, it will take up the full width of the parent element, center it's content
 // if it's smaller than the parent element, and overflow on the x-axis if it's larger.
 // For y-axis, it will either use a fixed height or dynamically adjust to the content.
 // Input: (x, y)
 // Block: (width: 100%, height: auto|y)
-// Child: (width: 100%|x, height: 100%)
+// Child: (width: 100% if the box fills the row or has a width, height: 100%)
 const BlockStyle = `
 div.goterm-row {
     /* Center the content */
@@ -71,12 +104,135 @@ div.goterm-box {
 }
   
 div.goterm-box > :first-child {
-    /* Override the width and height */
+    /* Override the height */
+    height: 100%;
+}
+
+div.goterm-box.goterm-fill > :first-child {
+    /* Override the width, only for a box which fills the row or has a fixed width */
     width: 100%;
+}
+`
+
+// Layout divs for multiple blocks in one row.
+// A flex row wraps its children, while a grid splits the row into equal columns.
+// Each cell centers a box which keeps the size of its own content.
+const LayoutStyle = `
+div.goterm-flex {
+    display: flex;
+    flex-wrap: wrap;
+    justify-content: space-around;
+    align-items: center;
+    gap: 8px;
+}
+
+div.goterm-grid {
+    display: grid;
+    gap: 8px;
+    align-items: center;
+}
+
+div.goterm-cell {
+    /* Center the box and scroll on x-axis like a row */
+    display: flex;
+    justify-content: space-around;
+    align-items: center;
+    overflow-x: auto;
+    overflow-y: hidden;
+    min-width: 0;
+}
+`
+
+// A centered column for the page content, see the MaxWidth option.
+const ContainerStyle = `
+.goterm-container {
+    /* center the column and keep the full height for the top level elements */
+    margin: 0 auto;
     height: 100%;
 }
 `
 
+// Separators between blocks.
+const SeparatorStyle = `
+hr.goterm-hr {
+    /* A thin line with some space around it */
+    border: none;
+    border-top: 1px solid #ddd;
+    margin: 16px 0;
+}
+`
+
+// Headings for structuring a report, in a document style rather than a terminal style.
+const HeadingStyle = `
+.goterm-heading {
+    font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif;
+    color: #24292f;
+    font-weight: 600;
+    line-height: 1.25;
+    margin: 0;
+    padding: 16px 8px 8px 8px;
+}
+h1.goterm-heading, h2.goterm-heading {
+    border-bottom: 1px solid #e5e5e5;
+}
+h1.goterm-heading { font-size: 2em; }
+h2.goterm-heading { font-size: 1.5em; }
+h3.goterm-heading { font-size: 1.25em; }
+h4.goterm-heading { font-size: 1em; }
+h5.goterm-heading { font-size: 0.875em; }
+h6.goterm-heading { font-size: 0.85em; color: #57606a; }
+`
+
+// Sections which group the output, see the Section function.
+const SectionStyle = `
+.goterm-section .goterm-section {
+    /* Indent a nested section with a guide line */
+    margin-left: 8px;
+    padding-left: 8px;
+    border-left: 2px solid #e5e5e5;
+}
+`
+
+// Figures with a caption, see the Captioned function.
+const CaptionStyle = `
+figure.goterm-figure {
+    display: flex;
+    flex-direction: column;
+    align-items: center;
+    margin: 0;
+}
+figcaption.goterm-caption {
+    font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif;
+    font-size: 0.875em;
+    color: #57606a;
+    padding: 8px;
+    text-align: center;
+}
+`
+
+// Table of contents, see the TOC function.
+const TOCStyle = `
+nav.goterm-toc {
+    font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif;
+    padding: 8px;
+}
+nav.goterm-toc ul {
+    list-style: none;
+    margin: 0;
+    padding: 0;
+}
+nav.goterm-toc li {
+    line-height: 1.75;
+}
+nav.goterm-toc a {
+    color: #0969da;
+    text-decoration: none;
+}
+nav.goterm-toc a:hover {
+    text-decoration: underline;
+}
+`
+
 const TextStyle = `
 pre.goterm {
     /* Background color similar to modern terminals */
@@ -119,44 +275,280 @@ pre.goterm {
 }
 `
 
+// PrintStyle makes a printed page, such as a PDF saved by the browser, a clean document, see the PrintMode option.
+const PrintStyle = `
+@media print {
+    html, body {
+        height: auto;
+        background-color: white;
+    }
+
+    /* Dark text on a light background saves ink */
+    pre.goterm {
+        background-color: white;
+        color: black;
+        border: 1px solid #ccc;
+        box-shadow: none;
+        overflow: visible;
+    }
+
+    /* Keep a block on one page, and a heading with the content after it */
+    div.goterm-row, div.goterm-cell {
+        break-inside: avoid;
+        page-break-inside: avoid;
+    }
+    .goterm-heading {
+        break-after: avoid;
+        page-break-after: avoid;
+    }
+
+    /* The controls don't work on paper */
+    form.goterm-input, a.goterm-download {
+        display: none;
+    }
+}
+`
+
+// ScrollScript follows the output by scrolling to the bottom of the page, as long as the user stays near the bottom.
+// It jumps instead of animating if the user prefers reduced motion, and pauses while the page is hidden.
 const ScrollScript = `
 <script>
+(function() {
+    const threshold = 100;
+    const reduceMotion = window.matchMedia('(prefers-reduced-motion: reduce)');
     let autoScroll = true;
-    let lastScrollTop = 0;
+    let lastScrollTop = window.scrollY;
+    let timer = null;
+
+    function distanceToBottom() {
+        return document.documentElement.scrollHeight - window.innerHeight - window.scrollY;
+    }
 
     // Function to scroll to the bottom of the page
     function scrollToBottom() {
-        if (autoScroll) {
-			window.scrollTo({
-                top: document.body.scrollHeight,
-                behavior: 'smooth'
+        if (autoScroll && distanceToBottom() > 1) {
+            window.scrollTo({
+                top: document.documentElement.scrollHeight,
+                behavior: reduceMotion.matches ? 'auto' : 'smooth'
             });
         }
     }
 
-    // Listen for scroll events to if we should stop auto-scrolling.
-	// This will set autoScroll to false if the user scrolls up.
+    // The script only scrolls down, so scrolling up away from the bottom is done by the user, which stops
+    // auto-scrolling. Coming back near the bottom restarts it. Small moves near the bottom, such as the
+    // bounce of a trackpad, don't stop it.
     window.addEventListener('scroll', function() {
-        let st = window.pageYOffset || document.documentElement.scrollTop;
-        // Check if the user is scrolling up
-        if (lastScrollTop - st >= 2) {
+        const st = window.scrollY;
+        if (distanceToBottom() <= threshold) {
+            autoScroll = true;
+        } else if (st < lastScrollTop) {
             autoScroll = false;
         }
-        lastScrollTop = st <= 0 ? 0 : st; // For Mobile or negative scrolling
-    });
+        lastScrollTop = st;
+    }, { passive: true });
 
-    // Periodically check if we should restart auto-scrolling.
-	// This will set autoScroll to true if the user is at the bottom of the page.
-    function checkScrollPosition() {
-        if (!autoScroll && (document.body.scrollHeight - window.innerHeight - window.scrollY <= 100)) {
-            autoScroll = true;
+    // Auto-scrolling is paused while the page is hidden
+    function start() {
+        if (timer === null) {
+            scrollToBottom();
+            timer = setInterval(scrollToBottom, 200);
         }
     }
+    function stop() {
+        clearInterval(timer);
+        timer = null;
+    }
+    document.addEventListener('visibilitychange', function() {
+        document.hidden ? stop() : start();
+    });
+    if (!document.hidden) {
+        start();
+    }
+})();
+</script>
+`
 
-    // Start auto-scrolling
-    setInterval(scrollToBottom, 200);
+// SSEScript receives the terminal output from the events endpoint and appends it to the page.
+// Scripts in HTML blocks are executed in order, waiting for external scripts to load.
+const SSEScript = `
+<script>
+(function() {
+    const main = document.getElementById('goterm-main');
+    let pre = null;
+    // The output goes into the innermost open section. A section is sent as an opening html block,
+    // which is a whole element here, and a closing html block.
+    const targets = [main];
+    let queue = Promise.resolve();
+
+    // Scripts inserted by innerHTML never run, so we replace them with new script elements.
+    function runScripts(scripts) {
+        let p = Promise.resolve();
+        scripts.forEach(function(old) {
+            p = p.then(function() {
+                return new Promise(function(resolve) {
+                    const s = document.createElement('script');
+                    for (const attr of old.attributes) {
+                        s.setAttribute(attr.name, attr.value);
+                    }
+                    s.text = old.text;
+                    if (s.src) {
+                        s.onload = resolve;
+                        s.onerror = resolve;
+                    }
+                    old.replaceWith(s);
+                    if (!s.src) {
+                        resolve();
+                    }
+                });
+            });
+        });
+        return p;
+    }
 
-    // Start checking scroll position every second
-    setInterval(checkScrollPosition, 1000);
+    // The id of an event is the number of lines received so far. The browser resumes from it with the
+    // Last-Event-ID header when it reconnects by itself, and a new source resumes from it with the "from"
+    // query parameter after the browser gives up. The first source passes on the query of the page.
+    let last = null;
+    let ended = false;
+    function connect() {
+        const source = new EventSource('events' + (last === null ? location.search : '?from=' + last));
+        source.addEventListener('text', function(e) {
+            last = e.lastEventId;
+            const line = JSON.parse(e.data);
+            queue = queue.then(function() {
+                if (!pre) {
+                    pre = document.createElement('pre');
+                    pre.className = 'goterm';
+                    targets[targets.length - 1].appendChild(pre);
+                }
+                pre.insertAdjacentHTML('beforeend', line + '\n');
+            });
+        });
+        source.addEventListener('html', function(e) {
+            last = e.lastEventId;
+            const html = JSON.parse(e.data);
+            queue = queue.then(function() {
+                pre = null;
+                if (html.trim() === '</section>') {
+                    if (targets.length > 1) {
+                        targets.pop();
+                    }
+                    return;
+                }
+                const tpl = document.createElement('template');
+                tpl.innerHTML = html;
+                const scripts = Array.from(tpl.content.querySelectorAll('script'));
+                const section = tpl.content.querySelector(':scope > section[data-goterm-open]');
+                targets[targets.length - 1].appendChild(tpl.content);
+                if (section) {
+                    targets.push(section);
+                }
+                return runScripts(scripts);
+            });
+        });
+        source.addEventListener('custom', function(e) {
+            const event = JSON.parse(e.data);
+            queue = queue.then(function() {
+                window.dispatchEvent(new CustomEvent('goterm:' + event.name, {detail: event.data}));
+            });
+        });
+        source.addEventListener('end', function() {
+            // All output has been received, do not reconnect
+            ended = true;
+            source.close();
+        });
+        source.addEventListener('error', function() {
+            if (!ended && source.readyState === EventSource.CLOSED) {
+                setTimeout(connect, 3000);
+            }
+        });
+    }
+    connect();
+})();
 </script>
 `
+
+// InputHTML is a form fixed at the bottom of the page which sends each submitted line to the input endpoint.
+const InputHTML = `
+<style>
+body {
+    /* Leave space for the input form */
+    padding-bottom: 3rem;
+}
+form.goterm-input {
+    position: fixed;
+    left: 0;
+    right: 0;
+    bottom: 0;
+    display: flex;
+    margin: 0;
+    padding: 0.5rem;
+    background-color: #1e1e1e;
+    border-top: 1px solid #333;
+}
+form.goterm-input input {
+    flex: 1 1 auto;
+    font-family: monaco, monospace, 'Consolas', 'Courier New';
+    font-size: 1rem;
+    color: hsl(0deg 0% 95%);
+    background-color: transparent;
+    border: none;
+    outline: none;
+}
+</style>
+<form class="goterm-input">
+    <input type="text" placeholder="Type and press Enter to send input" autocomplete="off">
+</form>
+<script>
+(function() {
+    const form = document.querySelector('form.goterm-input');
+    const input = form.querySelector('input');
+    // The endpoint is relative to the page, so it works under the prefix of a mounted Handler
+    const url = new URL('input', location.href);
+    url.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:';
+    let ws = null;
+
+    function connect() {
+        ws = new WebSocket(url.href);
+        ws.onclose = function() {
+            input.disabled = true;
+            input.placeholder = 'Input is closed';
+        };
+    }
+
+    form.addEventListener('submit', function(e) {
+        e.preventDefault();
+        if (ws && ws.readyState === WebSocket.OPEN) {
+            ws.send(input.value);
+            input.value = '';
+        }
+    });
+    connect();
+})();
+</script>
+`
+
+// DownloadHTML is a button fixed at the top right of the page which saves the current output as an HTML file.
+const DownloadHTML = `
+<style>
+a.goterm-download {
+    position: fixed;
+    top: 0.5rem;
+    right: 0.5rem;
+    z-index: 10;
+    padding: 0.25rem 0.75rem;
+    font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif;
+    font-size: 0.875rem;
+    color: #24292f;
+    text-decoration: none;
+    background-color: #f6f8fa;
+    border: 1px solid #d0d7de;
+    border-radius: 6px;
+    opacity: 0.8;
+}
+a.goterm-download:hover {
+    opacity: 1;
+}
+</style>
+<a class="goterm-download" href="download" download>Download</a>
+`
@@ -10,6 +10,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"iter"
@@ -19,8 +20,12 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -28,6 +33,20 @@ const (
 	// None html content will be wrapped in <pre> tag.
 	HtmlTag       = "==========76ADCBF0-980B-4C05-951F-63340F35E9C=========="
 	MaxBuffersize = 1024 * 1024 * 1024 // 1GB
+
+	// DropLine is returned by the function of the LineFilter option to drop a line entirely.
+	DropLine = "==========0B8E2F6A-3C1D-4A7E-9F25-D81C6E4B7A03=========="
+)
+
+var (
+	// ErrOpened is returned when opening a terminal which is already opened.
+	ErrOpened = errors.New("terminal is already opened")
+
+	// ErrNotOpened is returned when closing a terminal which is not opened or already closed.
+	ErrNotOpened = errors.New("terminal is not opened or already closed")
+
+	// ErrNotCustom is returned when getting the HTML content of a terminal whose format is not Custom.
+	ErrNotCustom = errors.New("format must be CustomFormat when calling HTML()")
 )
 
 // threadSafeWriter wraps io.Writer with a mutex for thread-safe writing
@@ -50,13 +69,23 @@ func (t *threadSafeWriter) Write(p []byte) (n int, err error) {
 }
 
 // Term captures stdout and stderr and provides methods to display the output in a browser.
+//
+// Concurrency model: the captured output is written to a buffer per stream, each of which has exactly one
+// reader: a pump goroutine that splits the output into lines and publishes them to a hub.
+// Every reader of the output, such as the iterator returned by HTML or a client of the web server,
+// subscribes to the hub and receives every line, so multiple readers can run concurrently.
+// When caching is enabled, the hub retains all lines so that a new reader replays the whole output
+// before following the live tail.
 type Term struct {
-	// Buffer to store the output
-	buf *Buffer
+	// Buffers to store the output of stdout and stderr, the content added by the Print methods goes to stdout
+	buf    *Buffer
+	errBuf *Buffer
+
+	// Hub to fan out the output lines to the readers, it's also the cache for the web server
+	hub *hub
 
-	// Cache to store the output for reuse in the web server
-	cache   bytes.Buffer
-	cacheMu sync.Mutex
+	// Hub to send the events of SendEvent to the pages, which are not kept
+	events *eventHub
 
 	// Pipes for attaching to stdout and stderr
 	stdoutWriter *os.File
@@ -70,19 +99,54 @@ type Term struct {
 
 	// Internal logger which writes to stderr
 	logger *log.Logger
-	opened bool
-	closed bool
+
+	opened  bool
+	closed  bool
+	closeMu sync.Mutex
 
 	// Options
-	format       OutputFormat
-	port         int
-	attachOutput bool
-	cacheOutput  bool
+	format        OutputFormat
+	port          int
+	bindHost      string
+	portFallback  bool
+	attachOutput  bool
+	captureStdout bool
+	captureStderr bool
+	cacheOutput   bool
+	sse           bool
+	input         bool
+	maxLineLength int
+	maxWidth      int
+	download      bool
+	noAutoScroll  bool
+	headHTML      []string
+	printMode     bool
+	teePath       string
+	lineFilter    func(line string) string
+	throttleLines int
+	output        io.Writer
+
+	// File which mirrors the captured output, see the TeeFile option
+	teeFile *os.File
+
+	// Writer of the content added by the Print methods, which also writes to the mirrors of stdout
+	out io.Writer
+
+	// Pipe for the text submitted from the browser
+	stdinReader *io.PipeReader
+	stdinWriter *io.PipeWriter
+
+	// Nesting level of the running Section calls
+	sectionDepth atomic.Int32
+
+	// Functions registered by OnBlock and OnLine, which the default terminal keeps when it's reopened
+	hooks *hookSet
 }
 
-func (t *Term) Open(options ...TermOption) {
+// Open starts capturing stdout and stderr. It returns ErrOpened if the terminal is already opened.
+func (t *Term) Open(options ...TermOption) error {
 	if t.opened {
-		panic("terminal is already opened")
+		return ErrOpened
 	}
 	t.opened = true
 
@@ -91,86 +155,98 @@ func (t *Term) Open(options ...TermOption) {
 		option(t)
 	}
 
-	// Save the original stdout and stderr
-	// t.oldStdout = os.Stdout
-	// t.oldStderr = os.Stderr
-
-	// Create pipes for stdout and stderr
-	stdoutReader, stdoutWriter, _ := os.Pipe()
-	stderrReader, stderrWriter, _ := os.Pipe()
-	t.stdoutWriter = stdoutWriter
-	t.stderrWriter = stderrWriter
-
-	// var err error
-	// err = syscall.SetNonblock(int(stdoutWriter.Fd()), true)
-	// if err != nil {
-	// 	log.Println(fmt.Errorf("set none block failed: %w", err))
-	// }
-
-	// Redirect stdout and stderr to the pipes
-	os.Stdout = stdoutWriter
-	os.Stderr = stderrWriter
-
-	// Set logger output to the buffer
-	log.SetOutput(os.Stderr)
-
-	// Start goroutines to copy the pipe contents to the buffer and original stdout/stderr
-	t.chWriterWg.Add(1)
-	go func() {
-		defer t.chWriterWg.Done()
+	// Listen before capturing the output, so that errors such as a port in use can be reported
+	var listener net.Listener
+	var err error
+	switch {
+	case t.format == HTMLWindow:
+		listener, err = t.listen(true, 0)
+	case t.format == Custom && t.port > 0:
+		listener, err = t.listenPort()
+	}
+	if err != nil {
+		t.opened = false
+		return err
+	}
 
-		defer stdoutReader.Close()
-		var err error
-		if t.format == Raw {
-			_, err = io.Copy(io.MultiWriter(t.buf, sysStdout), stdoutReader)
-		} else {
-			_, err = io.Copy(t.buf, stdoutReader)
-		}
+	// Create the file to mirror the output
+	if t.teePath != "" {
+		t.teeFile, err = os.Create(t.teePath)
 		if err != nil {
-			log.Printf("stdout copy error: %v", err)
+			t.opened = false
+			closeListener(listener)
+			return fmt.Errorf("create tee file: %w", err)
 		}
-	}()
-
-	t.chWriterWg.Add(1)
-	go func() {
-		defer t.chWriterWg.Done()
+	}
 
-		defer stderrReader.Close()
-		var err error
-		if t.format == Raw {
-			_, err = io.Copy(io.MultiWriter(t.buf, sysStderr), stderrReader)
-		} else {
-			_, err = io.Copy(t.buf, stderrReader)
-		}
-		if err != nil {
-			log.Printf("stderr copy error: %v", err)
+	// Both stdout and stderr are mirrored to the same file
+	var tee io.Writer
+	if t.teeFile != nil {
+		tee = NewThreadSafeWriter(t.teeFile)
+	}
+	t.out = t.mirror(t.buf, sysStdout, tee)
+
+	// Capture stdout and stderr, unless the terminal is detached
+	if t.attachOutput {
+		if err := t.attach(tee); err != nil {
+			t.opened = false
+			closeListener(listener)
+			t.closeTeeFile()
+			return err
 		}
+	}
+
+	// Start a goroutine per stream to publish the buffers to the hub.
+	// The output of the Custom format is retained, so that HTML can be called more than once.
+	// The download snapshot needs the whole output too, even the lines which a viewer has received.
+	t.hub.retain = t.cacheOutput || t.format == Custom || t.download
+	var pumps sync.WaitGroup
+	for stream, buf := range map[string]*Buffer{"stdout": t.buf, "stderr": t.errBuf} {
+		pumps.Add(1)
+		go func() {
+			defer pumps.Done()
+			t.pump(buf, stream)
+		}()
+	}
+	t.chReaderWg.Add(1)
+	go func() {
+		defer t.chReaderWg.Done()
+		pumps.Wait()
+		t.hub.close()
 	}()
 
-	// Start a goroutine to read the buffer
+	// Start a goroutine to read the output
 	t.chReaderWg.Add(1)
 	go func() {
 		defer t.chReaderWg.Done()
 
 		switch t.format {
 		case HTMLWindow:
-			t.serveHtmlContent(true, true, 0)
+			if err := t.serveHtmlContent(listener, true, true); err != nil {
+				t.logger.Printf("serve html content failed: %v", err)
+				t.discard()
+			}
 		case HTMLPage:
 			for html := range t.internalHTML(true) {
-				printToStdout(html)
+				t.printOutput(html)
 			}
 		case HTMLContent:
 			for html := range t.internalHTML(false) {
-				printToStdout(html)
+				t.printOutput(html)
 			}
 		case Raw:
-			for range t.internalHTML(false) {
-				// read and discard the output
+			t.discard()
+		case JSONLines:
+			for line := range t.jsonl() {
+				t.printOutput(line)
 			}
 		case Custom:
-			if t.port > 0 {
+			if listener != nil {
 				// start a web server to serve the terminal output
-				t.serveHtmlContent(false, false, t.port)
+				if err := t.serveHtmlContent(listener, false, false); err != nil {
+					t.logger.Printf("serve html content failed: %v", err)
+					t.discard()
+				}
 			} else {
 				// do nothing here, assuming the user will call HTML() to get the content
 			}
@@ -178,134 +254,313 @@ func (t *Term) Open(options ...TermOption) {
 			panic("unknown output format")
 		}
 	}()
+	return nil
+}
+
+// MustOpen is like Open but panics if the terminal can not be opened.
+// Opening a terminal twice is a misuse, so it's fine to use MustOpen in most programs.
+func (t *Term) MustOpen(options ...TermOption) {
+	if err := t.Open(options...); err != nil {
+		panic(err)
+	}
 }
 
 // Close stops capturing stdout and stderr and restores the original stdout and stderr.
+// It panics if the terminal is not opened or already closed, see TryClose for an error-returning alternative.
 func (t *Term) Close() {
-	// Restore stdout and stderr
-	os.Stdout = sysStdout
-	os.Stderr = sysStderr
-	log.SetOutput(sysStderr)
+	if err := t.TryClose(); err != nil {
+		panic(err)
+	}
+}
 
-	// Close writers to stop the goroutines
-	t.stdoutWriter.Close()
-	t.stderrWriter.Close()
+// TryClose is like Close but returns ErrNotOpened instead of panicking if the terminal is not opened or already closed.
+// It's safe to call it more than once, e.g. in a deferred call alongside an explicit close.
+func (t *Term) TryClose() error {
+	t.closeMu.Lock()
+	defer t.closeMu.Unlock()
+	if !t.opened || t.closed {
+		return ErrNotOpened
+	}
+
+	// Restore stdout and stderr, and close writers to stop the goroutines
+	if t.stdoutWriter != nil {
+		os.Stdout = sysStdout
+		t.stdoutWriter.Close()
+	}
+	if t.stderrWriter != nil {
+		os.Stderr = sysStderr
+		log.SetOutput(sysStderr)
+		t.stderrWriter.Close()
+	}
 
 	// Wait for channel writers
 	t.chWriterWg.Wait()
+	t.closeTeeFile()
+
+	// Stop receiving input from the browser
+	t.stdinWriter.Close()
 
-	// Close the channel
+	// Close the channels
 	t.buf.Close()
+	t.errBuf.Close()
 
 	// Wait for channel readers, including the web server and the iterator which the HTML() method returns
 	t.chReaderWg.Wait()
 
 	t.closed = true
+	return nil
 }
 
 // HTML returns a sequence of strings that represent the terminal output in HTML format.
 // If fullPage is true, the output will be wrapped in a full HTML page with styles.
 // Otherwise, the output will be some HTML content that can be embedded in a page.
+// The whole output is retained for the Custom format, so it can be called again, e.g. once for a page and once for a fragment.
+// It panics if the format is not Custom, see TryHTML for an error-returning alternative.
 func (t *Term) HTML(fullPage bool) iter.Seq[string] {
+	seq, err := t.TryHTML(fullPage)
+	if err != nil {
+		panic(err)
+	}
+	return seq
+}
+
+// TryHTML is like HTML but returns ErrNotCustom instead of panicking if the format is not Custom.
+func (t *Term) TryHTML(fullPage bool) (iter.Seq[string], error) {
 	if t.format != Custom {
-		panic("format must be CustomFormat when calling HTML()")
+		return nil, ErrNotCustom
 	}
-	return t.internalHTML(fullPage)
+	return t.internalHTML(fullPage), nil
 }
 
 func (t *Term) internalHTML(fullPage bool) iter.Seq[string] {
+	return t.render(fullPage, 0)
+}
+
+// render is like internalHTML, but skips the first from lines of the output.
+// If the skipped lines end in the middle of an HTML block, the rest of the block is skipped too.
+func (t *Term) render(fullPage bool, from int) iter.Seq[string] {
+	var prefix, suffix string
+	if fullPage {
+		prefix, suffix = t.getHtmlPagePrefix(), t.getHtmlPageSuffix()
+	}
+	return t.convert(t.lines(), from, prefix, suffix)
+}
+
+// snapshot returns a full page of the output which is available now, without the interactive controls.
+func (t *Term) snapshot() iter.Seq[string] {
+	return t.convert(texts(t.hub.snapshot()), 0, t.htmlPagePrefix(false), t.getHtmlPageSuffix())
+}
+
+// convert converts the lines to HTML, and wraps the result in the prefix and suffix if they are not empty.
+// It skips the first from lines like render does.
+func (t *Term) convert(lines iter.Seq[string], from int, prefix, suffix string) iter.Seq[string] {
 	return func(yield func(s string) bool) {
 		t.chReaderWg.Add(1)
 		defer t.chReaderWg.Done()
 
 		// Write html page prefix
-		if fullPage {
-			if !yield(t.getHtmlPagePrefix()) {
+		if prefix != "" {
+			if !yield(prefix) {
 				return
 			}
 		}
 
-		var sc *bufio.Scanner
-		inHtml := false
-		isFirstTextLine := true
+		// Convert text lines to html, text lines are wrapped in a pre tag
+		inText := false
+		inBlock := false
+		skipping := from > 0
+		for kind, line := range classify(lines) {
+			if skipping {
+				if kind == tagLine {
+					inBlock = !inBlock
+				}
+				// Stop skipping at the cursor, unless it's in the middle of an HTML block
+				from--
+				if from <= 0 && !inBlock {
+					skipping = false
+				}
+				continue
+			}
 
-		// convert text line to html
-		var convertLine = func(line string) bool {
-			// If the line is a tag line, discard it and toggle inHtml
-			if strings.HasSuffix(line, HtmlTag) {
-				if !inHtml && !isFirstTextLine {
+			switch kind {
+			case tagLine:
+				// Discard the tag line and close the pre tag if needed
+				if inText {
 					if !yield("</pre>\n") {
-						return false
+						return
 					}
 				}
-				inHtml = !inHtml
-				isFirstTextLine = true
-				return true // always skip the tag line
+				inText = false
+			case htmlLine:
+				// Yield html content directly
+				if !yield(line + "\n") {
+					return
+				}
+			case textLine:
+				if !inText {
+					inText = true
+					if !yield("<pre class=\"goterm\">\n") {
+						return
+					}
+				}
+				if !yield(line + "\n") {
+					return
+				}
+			}
+		}
+
+		// Reaching the end of the buffer, close the pre tag if needed
+		if inText {
+			if !yield("</pre>\n") {
+				return
 			}
+		}
 
-			// If the line is html content, yield it directly and return
-			if inHtml {
-				return yield(line + "\n")
+		// Write html page suffix
+		if suffix != "" {
+			if !yield(suffix) {
+				return
 			}
+		}
+	}
+}
 
-			// Otherwise, wrap the line in a pre tag
-			if isFirstTextLine {
-				isFirstTextLine = false
-				if !yield("<pre class=\"goterm\">\n") {
-					return false
+// pump is the only reader of the buffer of the stream, it publishes the captured lines to the hub.
+// The lines of an HTML block are published together, so that they are not mixed with the lines of the other stream.
+func (t *Term) pump(buf *Buffer, stream string) {
+	limit := t.maxLineLength
+	if limit <= 0 || limit > MaxBuffersize {
+		limit = MaxBuffersize
+	}
+	// A chunk must be able to hold a whole tag
+	limit = max(limit, 2*len(HtmlTag))
+
+	var block []record
+	sc := bufio.NewScanner(buf)
+	sc.Buffer(nil, limit+2) // two more bytes for the line ending
+	sc.Split(scanLines(limit))
+	for sc.Scan() {
+		now := time.Now()
+		for _, line := range splitTags(sc.Text()) {
+			isTag := strings.TrimRight(line, " \t\r") == HtmlTag
+			if !isTag && t.lineFilter != nil {
+				if line = t.lineFilter(line); line == DropLine {
+					continue
 				}
 			}
-			if !yield(line + "\n") {
-				return false
+			r := record{text: line, stream: stream, time: now}
+			switch {
+			case block == nil && !isTag:
+				t.hooks.run(&t.hooks.lines, line)
+				t.hub.push(r)
+			case block == nil:
+				// The start of an HTML block
+				block = []record{r}
+			default:
+				block = append(block, r)
+				if isTag {
+					t.hub.push(block...)
+					block = nil
+				}
 			}
-			return true
 		}
+	}
+	if block != nil {
+		t.hub.push(block...)
+	}
+	if err := sc.Err(); err != nil {
+		t.logger.Printf("read %s failed: %v", stream, err)
 
-		// Read cached output first
-		if t.cacheOutput {
-			t.cacheMu.Lock()
-			var savedStr = t.cache.String()
-			t.cacheMu.Unlock()
-
-			oldBuf := bytes.NewBufferString(savedStr)
-			sc = bufio.NewScanner(oldBuf)
-			sc.Buffer(nil, MaxBuffersize)
-			for sc.Scan() {
-				line := sc.Text()
-				if !convertLine(line) {
-					return
-				}
-			}
+		// Drain the buffer so that the writers will not be blocked
+		io.Copy(io.Discard, buf)
+	}
+}
+
+// scanLines is like bufio.ScanLines, which also drops the carriage return of a CRLF line ending,
+// but splits a line which is longer than limit bytes into chunks.
+// A chunk never ends in the middle of a UTF-8 character or an HtmlTag, so that the tag can still be detected.
+func scanLines(limit int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		// Look for the line ending after limit bytes, which might be CRLF
+		if len(data) <= limit+1 || bytes.IndexByte(data[:limit+2], '\n') >= 0 {
+			return bufio.ScanLines(data, atEOF)
 		}
 
-		// Read the buffer line by line
-		sc = bufio.NewScanner(t.buf)
-		sc.Buffer(nil, MaxBuffersize)
-		for sc.Scan() {
-			line := sc.Text()
-
-			// Update the cache
-			if t.cacheOutput {
-				t.cacheMu.Lock()
-				t.cache.WriteString(line + "\n")
-				t.cacheMu.Unlock()
-			}
+		// Move the cut back to the start of a character
+		cut := limit
+		for i := 0; i < utf8.UTFMax && cut > 0 && !utf8.RuneStart(data[cut]); i++ {
+			cut--
+		}
 
-			if !convertLine(line) {
-				return
+		// Move the cut back to the start of a partial tag, so that the tag is kept in the next chunk
+		for k := min(len(HtmlTag)-1, cut); k > 0; k-- {
+			if bytes.HasSuffix(data[:cut], []byte(HtmlTag[:k])) {
+				cut -= k
+				break
 			}
 		}
+		return cut, data[:cut], nil
+	}
+}
 
-		// Reaching the end of the buffer, close the pre tag if needed
-		if !inHtml && !isFirstTextLine {
-			if !yield("</pre>\n") {
+// splitTags puts each HtmlTag in the line on its own line, so that a tag which is printed
+// right after some text without a newline can still be detected.
+// Blank text around the tags, such as trailing spaces or a carriage return, is dropped.
+func splitTags(line string) []string {
+	if !strings.Contains(line, HtmlTag) {
+		return []string{line}
+	}
+	var lines []string
+	for {
+		before, after, found := strings.Cut(line, HtmlTag)
+		if strings.TrimSpace(before) != "" {
+			lines = append(lines, before)
+		}
+		if !found {
+			return lines
+		}
+		lines = append(lines, HtmlTag)
+		line = after
+	}
+}
+
+// lines returns a sequence of the captured lines. When caching is enabled, it starts with the cached output.
+func (t *Term) lines() iter.Seq[string] {
+	return texts(t.hub.subscribe())
+}
+
+// texts returns a sequence of the text of the records.
+func texts(records iter.Seq[record]) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for r := range records {
+			if !yield(r.text) {
 				return
 			}
 		}
+	}
+}
 
-		// Write html page suffix
-		if fullPage {
-			if !yield(t.getHtmlPageSuffix()) {
+type lineKind int
+
+const (
+	textLine lineKind = iota // Normal text which should be wrapped in a pre tag
+	htmlLine                 // HTML content between two tag lines
+	tagLine                  // The HtmlTag line which toggles between text and html
+)
+
+// classify tells whether each line is a text line, an html line or a tag line.
+func classify(lines iter.Seq[string]) iter.Seq2[lineKind, string] {
+	return func(yield func(lineKind, string) bool) {
+		inHtml := false
+		for line := range lines {
+			kind := textLine
+			if strings.TrimRight(line, " \t\r") == HtmlTag {
+				kind = tagLine
+				inHtml = !inHtml
+			} else if inHtml {
+				kind = htmlLine
+			}
+			if !yield(kind, line) {
 				return
 			}
 		}
@@ -313,143 +568,417 @@ func (t *Term) internalHTML(fullPage bool) iter.Seq[string] {
 }
 
 func (t *Term) getHtmlPagePrefix() string {
+	return t.htmlPagePrefix(true)
+}
+
+// htmlPagePrefix returns the head of the page. The interactive controls, such as the input form,
+// only work on a served page, so they can be left out.
+func (t *Term) htmlPagePrefix(interactive bool) string {
 	var buf bytes.Buffer
 
 	// write html head
 	buf.WriteString("<!DOCTYPE html>\n")
 	buf.WriteString("<html>\n")
 	buf.WriteString("<head>\n")
+	buf.WriteString("<meta charset=\"UTF-8\">\n")
 	buf.WriteString("<title>Term</title>\n")
+	// An empty icon, so that the browser will not request /favicon.ico
+	buf.WriteString("<link rel=\"icon\" href=\"data:,\">\n")
+	for _, h := range t.headHTML {
+		buf.WriteString(h)
+		buf.WriteString("\n")
+	}
 	buf.WriteString("</head>\n")
 	buf.WriteString("<body>\n")
 
 	// write css style
+	buf.WriteString(pageStyle())
+	if t.printMode {
+		buf.WriteString("<style>\n" + PrintStyle + "</style>\n")
+	}
+
+	// write script
+	if !t.noAutoScroll {
+		buf.WriteString(ScrollScript)
+	}
+	buf.WriteString(IframeResizeScript)
+
+	// write input form and download button
+	if interactive && t.input {
+		buf.WriteString(InputHTML)
+	}
+	if interactive && t.download {
+		buf.WriteString(DownloadHTML)
+	}
+
+	// wrap the content in a centered column
+	if t.maxWidth > 0 {
+		fmt.Fprintf(&buf, "<div class=\"goterm-container\" style=\"max-width: %dpx;\">\n", t.maxWidth)
+	}
+	return buf.String()
+}
+
+// pageStyle returns the style element for the elements of a page.
+func pageStyle() string {
+	var buf bytes.Buffer
 	buf.WriteString("<style>\n")
 	buf.WriteString(BodyStyle)
 	buf.WriteString(IframeStyle)
 	buf.WriteString(BlockStyle)
+	buf.WriteString(ContainerStyle)
+	buf.WriteString(LayoutStyle)
+	buf.WriteString(SeparatorStyle)
+	buf.WriteString(HeadingStyle)
+	buf.WriteString(SectionStyle)
+	buf.WriteString(CaptionStyle)
+	buf.WriteString(TOCStyle)
 	buf.WriteString(TextStyle)
 	buf.WriteString("</style>\n")
-
-	// write script
-	buf.WriteString(ScrollScript)
 	return buf.String()
 }
 
 func (t *Term) getHtmlPageSuffix() string {
 	var buf bytes.Buffer
+	if t.maxWidth > 0 {
+		buf.WriteString("</div>\n")
+	}
 	buf.WriteString("</body>\n")
 	buf.WriteString("</html>\n")
 	return buf.String()
 }
 
-func (t *Term) serveHtmlContent(local bool, serveOnce bool, port int) error {
-	var err error
-
-	// This WaitGroup is used only when serveOnce is true, otherwise the server will run indefinitely
-	var doneCh = make(chan any)
-	var doneOnce sync.Once
+// attach redirects stdout and stderr to pipes, and starts goroutines to copy the pipe contents
+// to the buffer and the other writers.
+func (t *Term) attach(tee io.Writer) error {
+	// Create pipes for the captured streams
+	if t.captureStdout {
+		w, err := t.pipe("stdout", t.buf, sysStdout, tee)
+		if err != nil {
+			return err
+		}
+		t.stdoutWriter = w
+	}
+	if t.captureStderr {
+		w, err := t.pipe("stderr", t.errBuf, sysStderr, tee)
+		if err != nil {
+			if t.stdoutWriter != nil {
+				t.stdoutWriter.Close()
+				t.stdoutWriter = nil
+			}
+			return err
+		}
+		t.stderrWriter = w
+	}
 
-	// Serve the HTML content
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		// The Close() method will wait for this WaitGroup to finish
-		t.chReaderWg.Add(1)
-		defer t.chReaderWg.Done()
+	// Redirect stdout and stderr to the pipes
+	if t.stdoutWriter != nil {
+		os.Stdout = t.stdoutWriter
+	}
+	if t.stderrWriter != nil {
+		os.Stderr = t.stderrWriter
 
-		// Get a Flusher to flush the response
-		flusher, ok := w.(http.Flusher)
-		if !ok {
-			http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
-			return
-		}
+		// Set logger output to the buffer
+		log.SetOutput(os.Stderr)
+	}
+	return nil
+}
 
-		// Set the Content-Type header so that the browser can render the HTML content immediately
-		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+// pipe creates a pipe for the stream with the given name, and starts a goroutine to copy the pipe contents
+// to the buffer of the stream and the original console. It returns the write end of the pipe.
+func (t *Term) pipe(name string, buf *Buffer, console *os.File, tee io.Writer) (*os.File, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("create %s pipe: %w", name, err)
+	}
 
-		for html := range t.internalHTML(true) {
-			// If client has disconnected, stop iterating and return
-			if r.Context().Err() != nil {
-				return
-			}
+	t.chWriterWg.Add(1)
+	go func() {
+		defer t.chWriterWg.Done()
 
-			// Flush some html content to the client
-			fmt.Fprint(w, html)
-			flusher.Flush()
+		defer reader.Close()
+		_, err := io.Copy(t.mirror(buf, console, tee), reader)
+		if err != nil {
+			log.Printf("%s copy error: %v", name, err)
 		}
+	}()
+	return writer, nil
+}
 
-		// One-time server will close the connection after serving the HTML content
-		if serveOnce {
-			doneOnce.Do(func() {
-				close(doneCh)
-			})
-		}
-	})
+// mirror returns the writer of the captured output, which also writes to the console in Raw format
+// and to the tee file if it's not nil.
+func (t *Term) mirror(buf *Buffer, console *os.File, tee io.Writer) io.Writer {
+	writers := []io.Writer{buf}
+	if t.format == Raw {
+		writers = append(writers, console)
+	}
+	if tee != nil {
+		writers = append(writers, tee)
+	}
+	if len(writers) == 1 {
+		return buf
+	}
+	return io.MultiWriter(writers...)
+}
+
+// closeTeeFile closes the tee file if it's opened.
+func (t *Term) closeTeeFile() {
+	if t.teeFile == nil {
+		return
+	}
+	if err := t.teeFile.Close(); err != nil {
+		t.logger.Printf("close tee file failed: %v", err)
+	}
+	t.teeFile = nil
+}
 
-	// Get host based on the local flag
+// discard reads and discards the output, so that the writers will not be blocked.
+func (t *Term) discard() {
+	for range t.internalHTML(false) {
+		// read and discard the output
+	}
+}
+
+// listen creates a listener for the web server. If port is not positive, a random port is used.
+func (t *Term) listen(local bool, port int) (net.Listener, error) {
+	// Get host based on the local flag, or the BindHost option
 	host := "localhost"
 	if !local {
 		host = "0.0.0.0"
 	}
+	if t.bindHost != "" {
+		host = t.bindHost
+	}
 
-	// Get port based on the given port or random port
-	var listener net.Listener
-	if port <= 0 {
-		// Listen on a random port
-		addr := fmt.Sprintf("%s:0", host)
-		listener, err = net.Listen("tcp", addr)
-		if err != nil {
-			return err
+	// Listen on the given port or a random port
+	addr := net.JoinHostPort(host, strconv.Itoa(max(port, 0)))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	return listener, nil
+}
+
+// listenPort creates the listener for the BindPort option. If the port is not available,
+// it falls back to a random port with the FallbackRandomPort option.
+func (t *Term) listenPort() (net.Listener, error) {
+	listener, err := t.listen(false, t.port)
+	if err != nil && t.portFallback {
+		t.logger.Printf("%v, using a random port instead", err)
+		return t.listen(false, 0)
+	}
+	return listener, err
+}
+
+// serverURLs returns the URLs of the web server. The server listening on all interfaces is reachable
+// at localhost and the addresses of the machine, which are listed after localhost.
+func (t *Term) serverURLs(local bool, port int) []string {
+	host := t.bindHost
+	if host == "" {
+		host = "localhost"
+		if !local {
+			host = "0.0.0.0"
 		}
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsUnspecified() {
+		return []string{serverURL(host, port)}
+	}
 
-		// Extract port from listener's address
-		port = listener.Addr().(*net.TCPAddr).Port
-	} else {
-		// Listen on the given port
-		addr := fmt.Sprintf("%s:%d", host, port)
-		listener, err = net.Listen("tcp", addr)
-		if err != nil {
-			return err
+	urls := []string{serverURL("localhost", port)}
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		t.logger.Printf("get interface addresses failed: %v", err)
+		return urls
+	}
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.IsLinkLocalUnicast() {
+			continue
 		}
+		urls = append(urls, serverURL(ipnet.IP.String(), port))
 	}
+	return urls
+}
+
+// interfaceAddrs uses var declaration to make it possible to override this function in tests.
+var interfaceAddrs = net.InterfaceAddrs
+
+// serverURL returns the URL of the web server at the host and the port.
+func serverURL(host string, port int) string {
+	if port == 80 {
+		// remove the port if it is 80
+		if strings.Contains(host, ":") {
+			return fmt.Sprintf("http://[%s]", host)
+		}
+		return "http://" + host
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// closeListener closes the listener if it's not nil.
+func closeListener(listener net.Listener) {
+	if listener != nil {
+		listener.Close()
+	}
+}
+
+// serveHtmlContent serves the HTML content on the given listener.
+// If serveOnce is true, it returns after the content has been served to a client.
+// Otherwise, it hangs forever.
+func (t *Term) serveHtmlContent(listener net.Listener, local bool, serveOnce bool) error {
+	// This WaitGroup is used only when serveOnce is true, otherwise the server will run indefinitely
+	var doneCh = make(chan any)
+	var doneOnce sync.Once
+
+	// One-time server will close the connection after serving the HTML content
+	mux := t.newServeMux(func() {
+		if serveOnce {
+			doneOnce.Do(func() {
+				close(doneCh)
+			})
+		}
+	})
 
 	// Create an HTTP server
-	server := &http.Server{}
+	server := &http.Server{Handler: mux}
 
 	// Start the HTTP server in a separate goroutine so that we can close it later using server.Shutdown()
+	serveErr := make(chan error, 1)
 	go func() {
 		if err := server.Serve(listener); err != http.ErrServerClosed {
 			t.logger.Printf("HTTP server ListenAndServe failed: %v", err)
+			serveErr <- err
 		}
 	}()
 
-	// Construct the URL based on the host and port
-	url := fmt.Sprintf("http://localhost:%d", port)
-	if port == 80 {
-		// remove the port if it is 80
-		url = "http://localhost"
-	}
+	// Construct the URLs based on the host and the port
+	urls := t.serverURLs(local, listener.Addr().(*net.TCPAddr).Port)
+	url := urls[0]
 
 	// Open or print the URL based on the local flag
 	if local {
-		// Open the URL in the default browser
-		err = openInBrower(url)
-		if err != nil {
-			return fmt.Errorf("openURL: %w", err)
+		// Open the URL in the default browser, or print it so that the user can open it manually
+		if err := openInBrower(url); err != nil {
+			t.logger.Printf("Open browser failed: %v, please open the URL manually: %s", err, url)
 		}
 	} else {
-		// Print the URL to the console
-		t.logger.Printf("Serving HTML content at: %s", url)
+		// Print the URLs to the console
+		t.logger.Printf("Serving HTML content at: %s", strings.Join(urls, ", "))
 	}
 
 	if serveOnce {
 		// Keep the program running until the HTML content is served
-		<-doneCh
+		select {
+		case <-doneCh:
+		case err := <-serveErr:
+			return err
+		}
 		server.Shutdown(context.Background())
 		return nil
 	}
 
 	// Hanging here so that the Close() method can wait for the server to finish
-	select {}
+	return <-serveErr
+}
+
+// Handler returns the handler of the web server which the BindPort option starts, without listening on a port.
+// It serves the output of a terminal opened with the Custom format, and the other options such as EnableSSE apply.
+// It's useful to test the served pages with httptest.NewServer, or to serve them with a custom server.
+func (t *Term) Handler() http.Handler {
+	if t.format != Custom {
+		panic(ErrNotCustom)
+	}
+	return t.newServeMux(func() {})
+}
+
+// newServeMux creates the handlers of the web server.
+// The served function is called after the whole output has been served to a client.
+func (t *Term) newServeMux(served func()) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// Answer favicon requests here, so that they will not start another stream
+	mux.HandleFunc("/favicon.ico", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if t.input {
+		mux.HandleFunc("/input", t.serveInput)
+	}
+
+	if t.download {
+		mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="term.html"`)
+			out := newGzipResponse(w, r)
+			defer out.Close()
+			for html := range t.snapshot() {
+				fmt.Fprint(out, html)
+			}
+		})
+	}
+
+	// Only the exact "/" route streams the output, other paths are not found
+	if t.sse {
+		// Serve a page shell and push the HTML content as server-sent events
+		mux.HandleFunc("/{$}", t.serveSSEPage)
+		mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+			t.chReaderWg.Add(1)
+			defer t.chReaderWg.Done()
+
+			if t.serveSSEEvents(w, r) {
+				served()
+			}
+		})
+		return mux
+	}
+
+	// Serve the HTML content
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		// The Close() method will wait for this WaitGroup to finish
+		t.chReaderWg.Add(1)
+		defer t.chReaderWg.Done()
+
+		// Make sure the response can be flushed
+		if _, ok := w.(http.Flusher); !ok {
+			http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+			return
+		}
+
+		// Set the Content-Type header so that the browser can render the HTML content immediately
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+
+		// Compress the response if the client accepts gzip, each chunk is still flushed unless throttled
+		out := t.throttle(newGzipResponse(w, r))
+		defer out.Close()
+
+		// The page can start from a line offset with the "from" query parameter, see cursor
+		for html := range t.render(true, t.cursor(r)) {
+			// If client has disconnected, stop iterating and return
+			if r.Context().Err() != nil {
+				return
+			}
+
+			// Flush some html content to the client
+			fmt.Fprint(out, html)
+			out.Flush()
+		}
+		served()
+	})
+	return mux
+}
+
+// cursor returns the line offset from the "from" query parameter of the request.
+// A negative value counts from the end of the cached output, so "?from=-100" shows the last 100 lines.
+// The page of the EnableSSE option passes its query to the events endpoint, and it sends the number of lines
+// which it has received when it reconnects after the browser gives up reconnecting with Last-Event-ID.
+func (t *Term) cursor(r *http.Request) int {
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		return 0
+	}
+	if from < 0 {
+		from = max(0, t.hub.len()+from)
+	}
+	return from
 }
 
 // NewTerm creates a new Term and copies stdout and stderr to a internal buffer.
@@ -457,12 +986,30 @@ func (t *Term) serveHtmlContent(local bool, serveOnce bool, port int) error {
 // See the Format options for other ways to display the output.
 func NewTerm() *Term {
 	term := &Term{
-		buf:    NewBuffer(),
-		logger: log.New(sysStderr, "", log.LstdFlags),
+		buf:           NewBuffer(),
+		errBuf:        NewBuffer(),
+		hub:           newHub(),
+		events:        newEventHub(),
+		hooks:         &hookSet{},
+		logger:        log.New(sysStderr, "", log.LstdFlags),
+		attachOutput:  true,
+		captureStdout: true,
+		captureStderr: true,
 	}
+	term.stdinReader, term.stdinWriter = io.Pipe()
 	return term
 }
 
+// printOutput prints the output of the HTMLPage, HTMLContent and JSONLines formats
+// to the writer of the OutputTo option, or to stdout.
+func (t *Term) printOutput(s string) {
+	if t.output != nil {
+		io.WriteString(t.output, s)
+		return
+	}
+	printToStdout(s)
+}
+
 // printToStdout uses var declaration to make it possible to override this function in tests.
 var printToStdout = func(s string) {
 	fmt.Fprint(sysStdout, s)
@@ -503,6 +1050,20 @@ func escapeHtml(html string) string {
 %s`, HtmlTag, html, HtmlTag)
 }
 
+// Print adds the text to the terminal output directly, without writing to stdout.
+// It's the way to add content to a detached terminal, see the Detach option.
+// It must be called between Open and Close.
+func (t *Term) Print(s string) {
+	t.out.Write([]byte(s))
+}
+
+// PrintHTMLBlock adds the block element to the terminal output directly, like the Block function does with stdout.
+func (t *Term) PrintHTMLBlock(e BlockElement, ops ...BlockOption) {
+	block := blockHTML(e.HTML(), 0, 0, elementOptions(e, ops...)...)
+	t.hooks.run(&t.hooks.blocks, block)
+	t.Print(escapeHtml(block) + "\n")
+}
+
 // PrintHtml prints the given HTML content to the terminal.
 func PrintHtml(html string) {
 	s := escapeHtml(html)
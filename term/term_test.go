@@ -1,12 +1,21 @@
 package term
 
 import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
+	"image/color"
 	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -49,11 +58,16 @@ func TestOpenInCustomFormat(t *testing.T) {
 }
 
 func TestOpenMultipleTimes(t *testing.T) {
-	// Open should panic if the terminal is already open.
+	// Open should return an error if the terminal is already open.
 	Open(Format(Custom))
 	defer Close()
+	if err := Open(Format(Custom)); err != ErrOpened {
+		t.Errorf("got %v, want %v", err, ErrOpened)
+	}
+
+	// MustOpen should panic instead.
 	assertPanic(t, func() {
-		Open(Format(Custom))
+		MustOpen(Format(Custom))
 	})
 }
 
@@ -88,6 +102,15 @@ func TestClose(t *testing.T) {
 	Open(Format(Custom))
 	Close()
 	assertPanic(t, Close)
+
+	// TryClose should be safe to call more than once.
+	Open(Format(Custom))
+	if err := TryClose(); err != nil {
+		t.Fatalf("TryClose() = %v, want nil", err)
+	}
+	if err := TryClose(); err != ErrNotOpened {
+		t.Fatalf("TryClose() = %v, want ErrNotOpened", err)
+	}
 }
 
 func TestHTML(t *testing.T) {
@@ -126,6 +149,9 @@ func TestHTML(t *testing.T) {
 			foo()
 		} else {
 			assertPanic(t, foo)
+			if _, err := TryHTML(false); err != ErrNotCustom {
+				t.Errorf("got %v, want %v", err, ErrNotCustom)
+			}
 		}
 
 	}
@@ -158,3 +184,1269 @@ func assertPanic(t *testing.T, f func()) {
 func preText(s string) string {
 	return fmt.Sprintf("<pre class=\"goterm\">\n%s\n</pre>\n", s)
 }
+
+func TestHeading(t *testing.T) {
+	got := Heading(2, "<b>Title</b>").HTML()
+	want := "<h2 class='goterm-heading'>&lt;b&gt;Title&lt;/b&gt;</h2>"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Levels are clamped to h1..h6.
+	if got := Heading(9, "x").HTML(); !strings.HasPrefix(got, "<h6") {
+		t.Errorf("got %q, want h6", got)
+	}
+}
+
+func TestSSE(t *testing.T) {
+	var body string
+	done := make(chan struct{})
+	holdOpen := openInBrower
+	openInBrower = func(url string) error {
+		go func() {
+			defer close(done)
+			resp, err := http.Get(url + "/events")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			body = string(b)
+		}()
+		return nil
+	}
+	defer func() { openInBrower = holdOpen }()
+
+	Open(Format(HTMLWindow), EnableSSE())
+	fmt.Println("hi")
+	PrintHtml("<span>a</span>\n<span>b</span>")
+	Close()
+	<-done
+
+	want := "id: 1\nevent: text\ndata: \"hi\"\n\n" +
+		"id: 5\nevent: html\ndata: \"\\u003cspan\\u003ea\\u003c/span\\u003e\\n\\u003cspan\\u003eb\\u003c/span\\u003e\"\n\n" +
+		"id: 5\nevent: end\ndata: \"\"\n\n"
+	if body != want {
+		t.Errorf("got %q, want %q", body, want)
+	}
+}
+
+func TestInput(t *testing.T) {
+	tm := NewTerm()
+	server := httptest.NewServer(http.HandlerFunc(tm.serveInput))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Handshake
+	fmt.Fprint(conn, "GET /input HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.Header.Get("Sec-WebSocket-Accept"), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Fatalf("got accept %q, want %q", got, want)
+	}
+
+	// Send a masked text frame
+	msg := []byte("hello")
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x81, 0x80 | byte(len(msg))}
+	frame = append(frame, mask...)
+	for i, b := range msg {
+		frame = append(frame, b^mask[i%4])
+	}
+	conn.Write(frame)
+
+	line, err := bufio.NewReader(tm.Stdin()).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "hello\n" {
+		t.Errorf("got %q, want %q", line, "hello\n")
+	}
+}
+
+func TestInputCrossOrigin(t *testing.T) {
+	tm := NewTerm()
+	server := httptest.NewServer(http.HandlerFunc(tm.serveInput))
+	defer server.Close()
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"", http.StatusSwitchingProtocols},
+		{server.URL, http.StatusSwitchingProtocols},
+		{"http://evil.example.com", http.StatusForbidden},
+		{"http://localhost:1", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := "GET /input HTTP/1.1\r\nHost: " + strings.TrimPrefix(server.URL, "http://") + "\r\n" +
+			"Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n"
+		if test.origin != "" {
+			header += "Origin: " + test.origin + "\r\n"
+		}
+		fmt.Fprint(conn, header+"\r\n")
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		conn.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != test.want {
+			t.Errorf("origin %q: got status %d, want %d", test.origin, resp.StatusCode, test.want)
+		}
+	}
+}
+
+func TestInputPrefix(t *testing.T) {
+	tm := NewTerm()
+	tm.Open(Format(Custom), EnableInput(), Detach())
+	defer tm.Close()
+	mux := http.NewServeMux()
+	mux.Handle("/app/", http.StripPrefix("/app", tm.Handler()))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// The page connects to the input endpoint relative to its own path
+	if !strings.Contains(InputHTML, "new URL('input', location.href)") || strings.Contains(InputHTML, "'/input'") {
+		t.Error("the input endpoint is not relative to the page")
+	}
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "GET /app/input HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+}
+
+func TestInputUnmasked(t *testing.T) {
+	tm := NewTerm()
+	server := httptest.NewServer(http.HandlerFunc(tm.serveInput))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	fmt.Fprint(conn, "GET /input HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	r := bufio.NewReader(conn)
+	if _, err := http.ReadResponse(r, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Send an unmasked text frame, the server should close with a protocol error
+	conn.Write(append([]byte{0x81, 5}, "hello"...))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	frame := make([]byte, 4)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x88, 2, 0x03, 0xea}; string(frame) != string(want) {
+		t.Errorf("got frame %v, want %v", frame, want)
+	}
+}
+
+func TestSSEFrom(t *testing.T) {
+	tm := NewTerm()
+	tm.Open(Format(Custom), EnableSSE())
+	tm.Print("a\nb\nc\n")
+	tm.Close()
+
+	tests := []struct {
+		query       string
+		lastEventID string
+		want        string
+	}{
+		{"", "", "abc"},
+		{"?from=1", "", "bc"},
+		{"?from=-1", "", "c"},
+		// The browser sends the id of the last event when it reconnects by itself
+		{"?from=-1", "1", "bc"},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/events"+test.query, nil)
+		if test.lastEventID != "" {
+			r.Header.Set("Last-Event-ID", test.lastEventID)
+		}
+		w := httptest.NewRecorder()
+		tm.newServeMux(func() {}).ServeHTTP(w, r)
+
+		var got string
+		for _, line := range strings.Split(w.Body.String(), "\n") {
+			if data, ok := strings.CutPrefix(line, "data: "); ok && data != `""` {
+				var s string
+				json.Unmarshal([]byte(data), &s)
+				got += s
+			}
+		}
+		if got != test.want {
+			t.Errorf("%q with Last-Event-ID %q: got %q, want %q", test.query, test.lastEventID, got, test.want)
+		}
+	}
+
+	// The page sends the number of lines which it has received when it connects again
+	if !strings.Contains(SSEScript, "'?from=' + last") {
+		t.Error("the page should resume from the last line it received")
+	}
+}
+
+func TestSSEResume(t *testing.T) {
+	tm := NewTerm()
+	tm.Open(Format(Custom), EnableSSE())
+	srv := httptest.NewServer(tm.Handler())
+	defer srv.Close()
+	defer tm.TryClose()
+
+	// get returns the text lines of the events, and the id of the last one
+	get := func(lastEventID string, n int) (lines []string, id string) {
+		req, _ := http.NewRequest("GET", srv.URL+"/events", nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		r := bufio.NewReader(resp.Body)
+		for len(lines) < n {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if v, ok := strings.CutPrefix(line, "id: "); ok {
+				id = v
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok && data != `""` {
+				var s string
+				json.Unmarshal([]byte(data), &s)
+				lines = append(lines, s)
+			}
+		}
+		return lines, id
+	}
+
+	tm.Print("a\nb\n")
+	got, id := get("", 2)
+	if id != "2" {
+		t.Fatalf("got id %q, want %q", id, "2")
+	}
+
+	// The client reconnects with the id of the last event it has received
+	tm.Print("c\nd\n")
+	tm.Close()
+	more, _ := get(id, math.MaxInt)
+	got = append(got, more...)
+	if want := []string{"a", "b", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("got lines %q, want %q", got, want)
+	}
+}
+
+func TestRenderFrom(t *testing.T) {
+	input := "a\n" + escapeHtml("<b>x</b>\n<b>y</b>") + "\nc"
+	tests := []struct {
+		from int
+		want string
+	}{
+		{0, preText("a") + "<b>x</b>\n<b>y</b>\n" + preText("c")},
+		{1, "<b>x</b>\n<b>y</b>\n" + preText("c")},
+		// Starting in the middle of an HTML block skips the rest of the block.
+		{3, preText("c")},
+		{100, ""},
+	}
+
+	for _, test := range tests {
+		Open(Format(Custom))
+		fmt.Println(input)
+		Close()
+
+		got := strings.Join(slices.Collect(term.render(false, test.from)), "")
+		if got != test.want {
+			t.Errorf("from %d: got %q, want %q", test.from, got, test.want)
+		}
+	}
+}
+
+func TestServeNotFound(t *testing.T) {
+	tm := NewTerm()
+	mux := tm.newServeMux(func() {
+		t.Error("stream should not be served")
+	})
+
+	for _, path := range []string{"/foo", "/robots.txt", "/index.html"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: got status %d, want %d", path, w.Code, http.StatusNotFound)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/favicon.ico", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("favicon: got status %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestMultipleClients(t *testing.T) {
+	tm := NewTerm()
+	tm.cacheOutput = true
+	tm.Open(Format(Custom))
+	server := httptest.NewServer(tm.newServeMux(func() {}))
+	defer server.Close()
+
+	// Start two clients and print some lines while they are reading
+	bodies := make([]string, 2)
+	done := make(chan struct{})
+	for i := range bodies {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			bodies[i] = string(b)
+		}()
+	}
+
+	// Wait for both clients to subscribe
+	for subscribers := 0; subscribers < 2; {
+		time.Sleep(10 * time.Millisecond)
+		tm.hub.mu.Lock()
+		subscribers = len(tm.hub.cursors)
+		tm.hub.mu.Unlock()
+	}
+
+	for i := 0; i < 100; i++ {
+		fmt.Println("line", i)
+	}
+	tm.Close()
+	<-done
+	<-done
+
+	if bodies[0] != bodies[1] {
+		t.Errorf("clients got different output:\n%q\n%q", bodies[0], bodies[1])
+	}
+	for i := 0; i < 100; i++ {
+		if !strings.Contains(bodies[0], fmt.Sprintf("line %d\n", i)) {
+			t.Errorf("line %d is missing", i)
+		}
+	}
+}
+
+func TestMaxLineLength(t *testing.T) {
+	const limit = 200
+	long := strings.Repeat("a", 450)
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{long, preText(long[:200] + "\n" + long[200:400] + "\n" + long[400:])},
+		// The tag is kept in one chunk, so the html is still detected.
+		{long[:190] + escapeHtml("<b>x</b>"), preText(long[:190]) + "<b>x</b>\n"},
+		// A CRLF line ending right after the limit does not make an empty chunk.
+		{long[:200] + "\r", preText(long[:200])},
+		// Multi-byte characters are not split.
+		{"a" + strings.Repeat("世", 100), preText("a" + strings.Repeat("世", 66) + "\n" + strings.Repeat("世", 34))},
+	}
+
+	for _, test := range tests {
+		Open(Format(Custom), MaxLineLength(limit))
+		fmt.Println(test.input)
+		Close()
+
+		got := strings.Join(slices.Collect(HTML(false)), "")
+		if got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestHtmlTagDetection(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// CRLF line endings and trailing spaces around the tags
+		{"a\r\n" + HtmlTag + "  \r\n<b>x</b>\r\n" + HtmlTag + "\t\r\nb\r\n", preText("a") + "<b>x</b>\n" + preText("b")},
+		// The tag is printed in the middle of a line
+		{"a" + HtmlTag + "<b>x</b>" + HtmlTag + "b", preText("a") + "<b>x</b>\n" + preText("b")},
+	}
+
+	for _, test := range tests {
+		Open(Format(Custom))
+		fmt.Print(test.input)
+		Close()
+
+		got := strings.Join(slices.Collect(HTML(false)), "")
+		if got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestCRLF(t *testing.T) {
+	Open(Format(Custom))
+	fmt.Print("a\r\nb\r\n")
+	Close()
+
+	got := strings.Join(slices.Collect(HTML(false)), "")
+	if want := preText("a\nb"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTeeFile(t *testing.T) {
+	path := t.TempDir() + "/out.txt"
+
+	holdPrint := printToStdout
+	printToStdout = func(string) {}
+	defer func() { printToStdout = holdPrint }()
+
+	Open(Format(HTMLContent), TeeFile(path))
+	fmt.Println("a")
+	fmt.Fprintln(os.Stderr, "b")
+	Close()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != "a\nb\n" && got != "b\na\n" {
+		t.Errorf("got %q, want both lines", got)
+	}
+
+	// The file can not be created
+	if err := Open(TeeFile(t.TempDir() + "/no/such/dir/out.txt")); err == nil {
+		Close()
+		t.Error("got nil, want an error")
+	}
+	term = NewTerm()
+}
+
+func TestReader(t *testing.T) {
+	tm := NewTerm()
+	tm.Open(Format(Custom))
+	r := tm.Reader()
+	fmt.Println("a")
+	PrintHtml("<b>x</b>")
+	fmt.Println("b")
+	tm.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "a\n<b>x</b>\nb\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	tm := NewTerm()
+	tm.Open(Format(Custom), Detach())
+	if os.Stdout != sysStdout {
+		t.Error("stdout should not be redirected")
+	}
+	tm.Print("a\n")
+	tm.PrintHTMLBlock(HR())
+	tm.Print("b")
+	tm.Close()
+
+	got := strings.Join(slices.Collect(tm.HTML(false)), "")
+	want := preText("a") + "<div class='goterm-row'><div style='width: 100%;overflow-x: auto;' class='goterm-box goterm-fill'><hr class='goterm-hr'></div></div>\n" + preText("b")
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCaptureStderr(t *testing.T) {
+	tm := NewTerm()
+	tm.Open(Format(Custom), CaptureStdout(false))
+	if os.Stdout != sysStdout {
+		t.Error("stdout should not be redirected")
+	}
+	if os.Stderr == sysStderr {
+		t.Error("stderr should be redirected")
+	}
+	fmt.Fprintln(os.Stderr, "err")
+	tm.Close()
+
+	got := strings.Join(slices.Collect(tm.HTML(false)), "")
+	if want := preText("err"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSection(t *testing.T) {
+	Open(Format(Custom))
+	Section("outer", func() {
+		fmt.Println("a")
+		Section("<inner>", func() {
+			fmt.Println("b")
+		})
+	})
+	Close()
+
+	got := strings.Join(slices.Collect(HTML(false)), "")
+	want := "<section class='goterm-section' data-goterm-open><h2 class='goterm-heading'>outer</h2>\n" + preText("a") +
+		"<section class='goterm-section' data-goterm-open><h3 class='goterm-heading'>&lt;inner&gt;</h3>\n" + preText("b") +
+		"</section>\n</section>\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTermSection(t *testing.T) {
+	tm := NewTerm()
+	tm.Open(Format(Custom), Detach())
+	// The nesting of a terminal doesn't depend on the sections of another one
+	Open(Format(Custom))
+	defer Close()
+	Section("default", func() {
+		tm.Section("outer", func() {
+			tm.Print("a\n")
+			tm.Section("inner", func() {})
+		})
+	})
+	tm.Close()
+
+	got := strings.Join(slices.Collect(tm.HTML(false)), "")
+	want := "<section class='goterm-section' data-goterm-open><h2 class='goterm-heading'>outer</h2>\n" + preText("a") +
+		"<section class='goterm-section' data-goterm-open><h3 class='goterm-heading'>inner</h3>\n" +
+		"</section>\n</section>\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	tm := NewTerm()
+	tm.Open(Format(Custom))
+	fmt.Println("a")
+	// Wait for the line to be captured, the streams are not ordered with each other
+	for tm.hub.len() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	fmt.Fprintln(os.Stderr, "b")
+	for tm.hub.len() == 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	PrintHtml("<b>c</b>")
+	tm.Close()
+
+	var got []jsonRecord
+	for line := range tm.JSONL() {
+		var r jsonRecord
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("unmarshal %q: %v", line, err)
+		}
+		if r.TS.IsZero() {
+			t.Errorf("missing time in %q", line)
+		}
+		r.TS = time.Time{}
+		got = append(got, r)
+	}
+	want := []jsonRecord{
+		{Type: "text", Data: "a", Stream: "stdout"},
+		{Type: "text", Data: "b", Stream: "stderr"},
+		{Type: "html", Data: "<b>c</b>", Stream: "stdout"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLineFilter(t *testing.T) {
+	Open(Format(Custom), LineFilter(func(line string) string {
+		if strings.HasPrefix(line, "debug") {
+			return DropLine
+		}
+		return strings.ReplaceAll(line, "secret", "***")
+	}))
+	fmt.Println("token: secret")
+	fmt.Println("debug: secret")
+	PrintHtml("<b>secret</b>")
+	Close()
+
+	got := strings.Join(slices.Collect(HTML(false)), "")
+	want := preText("token: ***") + "<b>***</b>\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// flushCounter is a response recorder which counts the flushes.
+type flushCounter struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (f *flushCounter) Flush() {
+	f.flushes++
+	f.ResponseRecorder.Flush()
+}
+
+func TestThrottleLines(t *testing.T) {
+	tm := NewTerm()
+	ThrottleLines(20)(tm)
+	rec := &flushCounter{ResponseRecorder: httptest.NewRecorder()}
+	out := tm.throttle(newGzipResponse(rec, httptest.NewRequest("GET", "/", nil)))
+
+	// A burst is flushed once, and the rest is flushed by the timer
+	for i := range 10 {
+		fmt.Fprintln(out, i)
+		out.Flush()
+	}
+	out.(*throttledResponse).mu.Lock()
+	if rec.flushes != 1 {
+		t.Errorf("got %d flushes, want 1", rec.flushes)
+	}
+	out.(*throttledResponse).mu.Unlock()
+	time.Sleep(100 * time.Millisecond)
+	out.Close()
+	if rec.flushes != 2 {
+		t.Errorf("got %d flushes, want 2", rec.flushes)
+	}
+	if got := strings.Count(rec.Body.String(), "\n"); got != 10 {
+		t.Errorf("got %d lines, want 10", got)
+	}
+}
+
+func TestBufferRetain(t *testing.T) {
+	b := NewBufferRetain()
+	b.WriteString("hello ")
+	b.WriteString("world")
+	if b.Len() != 11 || b.String() != "hello world" {
+		t.Fatalf("got %d %q, want 11 %q", b.Len(), b.String(), "hello world")
+	}
+
+	// Reading consumes the data, but inspecting doesn't
+	p := make([]byte, 8)
+	n, _ := b.Read(p)
+	if got := string(p[:n]); got != "hello " {
+		t.Errorf("got %q, want %q", got, "hello ")
+	}
+	if got := string(b.Bytes()); got != "world" || b.Len() != 5 {
+		t.Errorf("got %d %q, want 5 %q", b.Len(), got, "world")
+	}
+	b.Close()
+	if got, _ := io.ReadAll(b); string(got) != "world" || b.Len() != 0 {
+		t.Errorf("got %d %q, want 0 %q", b.Len(), got, "world")
+	}
+}
+
+func TestHTMLTwice(t *testing.T) {
+	Open(Format(Custom))
+	fmt.Println("hi")
+	Close()
+
+	page := strings.Join(slices.Collect(HTML(true)), "")
+	if !strings.Contains(page, preText("hi")) {
+		t.Errorf("got %q, want it to contain %q", page, preText("hi"))
+	}
+	if got := strings.Join(slices.Collect(HTML(false)), ""); got != preText("hi") {
+		t.Errorf("got %q, want %q", got, preText("hi"))
+	}
+}
+
+func TestOutputTo(t *testing.T) {
+	var out strings.Builder
+	Open(Format(HTMLContent), OutputTo(&out))
+	fmt.Println("hi")
+	Close()
+
+	if got := out.String(); got != preText("hi") {
+		t.Errorf("got %q, want %q", got, preText("hi"))
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	got := Sparkline([]float64{1, 3, math.NaN(), 2, 2}).HTML()
+	for _, want := range []string{
+		`<polyline points="0.00,95.00 25.00,5.00" `,
+		`<polyline points="75.00,50.00 100.00,50.00" `,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q, want it to contain %q", got, want)
+		}
+	}
+
+	// The default size can be overridden
+	e := Sparkline(nil, SizeOption(60, 20))
+	got = blockHTML(e.HTML(), 0, 0, elementOptions(e)...)
+	if !strings.Contains(got, "width: 60px;height: 20px;") {
+		t.Errorf("got %q, want the size of 60x20", got)
+	}
+}
+
+func TestMaxWidth(t *testing.T) {
+	Open(Format(Custom), MaxWidth(800))
+	fmt.Println("hi")
+	Close()
+
+	got := strings.Join(slices.Collect(HTML(true)), "")
+	want := "<div class=\"goterm-container\" style=\"max-width: 800px;\">\n" + preText("hi") + "</div>\n</body>"
+	if !strings.Contains(got, want) {
+		t.Errorf("got %q, want it to contain %q", got, want)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	tm := NewTerm()
+	tm.cacheOutput = true
+	tm.Open(Format(Custom), EnableDownload())
+	defer tm.Close()
+	fmt.Println("hi")
+
+	// Wait for the line to be captured
+	for tm.hub.len() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The download returns a snapshot while the terminal is still open
+	w := httptest.NewRecorder()
+	tm.newServeMux(func() {}).ServeHTTP(w, httptest.NewRequest("GET", "/download", nil))
+	if got := w.Header().Get("Content-Disposition"); !strings.HasPrefix(got, "attachment") {
+		t.Errorf("got Content-Disposition %q, want an attachment", got)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, preText("hi")) {
+		t.Errorf("got %q, want it to contain the output", body)
+	}
+	if strings.Contains(body, "goterm-download") {
+		t.Error("the snapshot should not contain the download button")
+	}
+	if page := tm.getHtmlPagePrefix(); !strings.Contains(page, "goterm-download") {
+		t.Error("the served page should contain the download button")
+	}
+}
+
+func TestDownloadPrefix(t *testing.T) {
+	tm := NewTerm()
+	tm.Open(Format(Custom), EnableDownload(), Detach())
+	tm.Print("hi\n")
+	defer tm.Close()
+	mux := http.NewServeMux()
+	mux.Handle("/app/", http.StripPrefix("/app", tm.Handler()))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// The link is relative to the page, so it works under the prefix
+	if !strings.Contains(DownloadHTML, `href="download"`) {
+		t.Error("the download link is not relative to the page")
+	}
+	resp, err := http.Get(server.URL + "/app/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "hi") {
+		t.Errorf("got status %d and %q, want the snapshot", resp.StatusCode, body)
+	}
+}
+
+func TestDownloadNoCache(t *testing.T) {
+	urls := make(chan string, 1)
+	holdOpen := openInBrower
+	openInBrower = func(url string) error {
+		urls <- url
+		return nil
+	}
+	defer func() { openInBrower = holdOpen }()
+
+	tm := NewTerm()
+	tm.Open(Format(HTMLWindow), EnableDownload())
+	defer tm.Close()
+	url := <-urls
+
+	// A viewer receives the line before the download
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	tm.Print("hi\n")
+	r := bufio.NewReader(resp.Body)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(line, "hi") {
+			break
+		}
+	}
+
+	resp, err = http.Get(url + "/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), preText("hi")) {
+		t.Errorf("got %q, want it to contain the output", body)
+	}
+}
+
+func TestSendEvent(t *testing.T) {
+	urls := make(chan string, 1)
+	holdOpen := openInBrower
+	openInBrower = func(url string) error {
+		urls <- url
+		return nil
+	}
+	defer func() { openInBrower = holdOpen }()
+
+	tm := NewTerm()
+	tm.Open(Format(HTMLWindow), EnableSSE())
+	defer tm.Close()
+	url := <-urls
+
+	resp, err := http.Get(url + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	tm.Print("hi\n")
+	r := bufio.NewReader(resp.Body)
+	readUntil := func(prefix string) string {
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		}
+	}
+	readUntil("event: text")
+
+	lines := tm.hub.len()
+	if err := tm.SendEvent("point", map[string]int{"x": 1}); err != nil {
+		t.Fatal(err)
+	}
+	readUntil("event: custom")
+	if got, want := readUntil("data: "), "data: {\"name\":\"point\",\"data\":{\"x\":1}}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := tm.hub.len(); got != lines {
+		t.Errorf("the event was added to the output: got %d lines, want %d", got, lines)
+	}
+}
+
+func TestNoAutoScroll(t *testing.T) {
+	tm := NewTerm()
+	if page := tm.getHtmlPagePrefix(); !strings.Contains(page, "scrollToBottom") {
+		t.Error("the page should contain the scroll script by default")
+	}
+	NoAutoScroll()(tm)
+	if page := tm.getHtmlPagePrefix(); strings.Contains(page, "scrollToBottom") {
+		t.Error("the page should not contain the scroll script")
+	}
+}
+
+func TestHeadHTML(t *testing.T) {
+	tm := NewTerm()
+	AddScript("https://example.com/lib.js?a=1&b=\"2\"")(tm)
+	HeadHTML("<style>p {}</style>")(tm)
+	page := tm.getHtmlPagePrefix()
+	want := "<script src=\"https://example.com/lib.js?a=1&amp;b=&#34;2&#34;\"></script>\n<style>p {}</style>\n</head>"
+	if !strings.Contains(page, want) {
+		t.Errorf("got %q, want it to contain %q", page, want)
+	}
+}
+
+func TestIframeHeight(t *testing.T) {
+	got := EscapeIframe("<html><body>hi</body></html>", "")
+	if !strings.Contains(got, "gotermHeight") || !strings.Contains(got, "&lt;/script&gt;\n&lt;/body&gt;") {
+		t.Errorf("got %q, want the height script in the body", got)
+	}
+	if got := EscapeIframe("https://example.com", ""); strings.Contains(got, "gotermHeight") {
+		t.Errorf("got %q, want no script for a url", got)
+	}
+	if page := NewTerm().getHtmlPagePrefix(); !strings.Contains(page, IframeResizeScript) {
+		t.Error("the page should contain the iframe resize script")
+	}
+}
+
+func TestSandboxOption(t *testing.T) {
+	page := "<html><head></head><body>hi</body></html>"
+	got := blockHTML(page, 0, 0, SandboxOption("allow-scripts"), CSPOption("script-src 'none'"))
+	if !strings.Contains(got, `<iframe sandbox="allow-scripts" class=""`) {
+		t.Errorf("got %q, want a sandboxed iframe", got)
+	}
+	if !strings.Contains(got, "&lt;head&gt;&lt;meta http-equiv=&#34;Content-Security-Policy&#34; content=&#34;script-src &amp;#39;none&amp;#39;&#34;&gt;") {
+		t.Errorf("got %q, want the CSP meta in the head", got)
+	}
+	if got := blockHTML(page, 0, 0); strings.Contains(got, "sandbox") || strings.Contains(got, "Content-Security-Policy") {
+		t.Errorf("got %q, want no sandbox by default", got)
+	}
+}
+
+func TestPrintMode(t *testing.T) {
+	tm := NewTerm()
+	if page := tm.getHtmlPagePrefix(); strings.Contains(page, "@media print") {
+		t.Error("the page should not contain the print styles by default")
+	}
+	PrintMode()(tm)
+	if page := tm.getHtmlPagePrefix(); !strings.Contains(page, PrintStyle) {
+		t.Error("the page should contain the print styles")
+	}
+}
+
+func TestBindHost(t *testing.T) {
+	tm := NewTerm()
+	BindHost("127.0.0.1")(tm)
+	listener, err := tm.listen(false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	if ip := listener.Addr().(*net.TCPAddr).IP; !ip.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("got %v, want 127.0.0.1", ip)
+	}
+
+	for _, tc := range []struct {
+		host string
+		port int
+		want string
+	}{
+		{"localhost", 8080, "http://localhost:8080"},
+		{"localhost", 80, "http://localhost"},
+		{"::1", 8080, "http://[::1]:8080"},
+		{"::1", 80, "http://[::1]"},
+	} {
+		if got := serverURL(tc.host, tc.port); got != tc.want {
+			t.Errorf("serverURL(%q, %d) = %q, want %q", tc.host, tc.port, got, tc.want)
+		}
+	}
+}
+
+func TestServerURLs(t *testing.T) {
+	old := interfaceAddrs
+	defer func() { interfaceAddrs = old }()
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.IPv4(127, 0, 0, 1)},
+			&net.IPNet{IP: net.IPv4(192, 168, 1, 5)},
+			&net.IPNet{IP: net.ParseIP("fe80::1")},
+		}, nil
+	}
+
+	tm := NewTerm()
+	want := []string{"http://localhost:8080", "http://192.168.1.5:8080"}
+	if got := tm.serverURLs(false, 8080); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := tm.serverURLs(true, 8080); !slices.Equal(got, want[:1]) {
+		t.Errorf("got %v, want %v", got, want[:1])
+	}
+	BindHost("10.0.0.2")(tm)
+	if got := tm.serverURLs(false, 8080); !slices.Equal(got, []string{"http://10.0.0.2:8080"}) {
+		t.Errorf("got %v, want the bind host", got)
+	}
+}
+
+func TestFallbackRandomPort(t *testing.T) {
+	used, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer used.Close()
+	port := used.Addr().(*net.TCPAddr).Port
+
+	tm := NewTerm()
+	tm.logger = log.New(io.Discard, "", 0)
+	BindHost("localhost")(tm)
+	BindPort(port)(tm)
+	if _, err := tm.listenPort(); err == nil {
+		t.Fatal("listening on a used port should fail")
+	}
+
+	FallbackRandomPort()(tm)
+	listener, err := tm.listenPort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	if got := listener.Addr().(*net.TCPAddr).Port; got == port {
+		t.Errorf("got port %d, want a random port", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	Open(Format(Custom))
+	fmt.Println("hi")
+	Close()
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), preText("hi")) {
+		t.Errorf("got %q, want it to contain the output", body)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var mu sync.Mutex
+	var blocks, lines []string
+	removeBlock := OnBlock(func(html string) {
+		mu.Lock()
+		defer mu.Unlock()
+		blocks = append(blocks, html)
+	})
+	removeLine := OnLine(func(line string) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, line)
+	})
+
+	Open(Format(Custom))
+	fmt.Println("hi")
+	PrintBlock("<b>block</b>")
+	Close()
+	removeBlock()
+	removeLine()
+
+	Open(Format(Custom))
+	fmt.Println("removed")
+	PrintBlock("<b>removed</b>")
+	Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(blocks) != 1 || !strings.Contains(blocks[0], "<b>block</b>") {
+		t.Errorf("got blocks %q, want the block", blocks)
+	}
+	if !slices.Equal(lines, []string{"hi"}) {
+		t.Errorf("got lines %q, want [hi]", lines)
+	}
+}
+
+func TestTermHooks(t *testing.T) {
+	var blocks, lines, other []string
+	tm := NewTerm()
+	tm.Open(Format(Custom), Detach(), BlockHook(func(html string) {
+		blocks = append(blocks, html)
+	}))
+	tm.OnLine(func(line string) {
+		lines = append(lines, line)
+	})
+	// The hooks of another terminal are not called
+	remove := OnBlock(func(html string) {
+		other = append(other, html)
+	})
+	defer remove()
+	tm.PrintHTMLBlock(Heading(2, "block"))
+	tm.Print("hi\n")
+	tm.Close()
+
+	if len(blocks) != 1 || !strings.Contains(blocks[0], ">block</h2>") {
+		t.Errorf("got blocks %q, want the block", blocks)
+	}
+	if !slices.Equal(lines, []string{"hi"}) {
+		t.Errorf("got lines %q, want [hi]", lines)
+	}
+	if len(other) != 0 {
+		t.Errorf("got blocks %q in the default terminal, want none", other)
+	}
+}
+
+func TestBlockFill(t *testing.T) {
+	for _, tc := range []struct {
+		html string
+		ops  []BlockOption
+		want string
+	}{
+		{"<svg></svg>", nil, "class='goterm-box'>"},
+		{"<svg></svg>", []BlockOption{SizeOption(120, 32)}, "class='goterm-box goterm-fill'>"},
+		{"<iframe></iframe>", nil, "class='goterm-box goterm-fill'>"},
+	} {
+		if got := blockHTML(tc.html, 0, 0, tc.ops...); !strings.Contains(got, tc.want) {
+			t.Errorf("got %q, want it to contain %q", got, tc.want)
+		}
+	}
+}
+
+func TestIframeColor(t *testing.T) {
+	page := "<html><head></head><body>hi</body></html>"
+	got := blockHTML(page, 0, 0, ColorOption(color.RGBA{R: 255, A: 255}))
+	if !strings.Contains(got, "&lt;head&gt;&lt;style&gt;body { color: rgba(255, 0, 0, 1.00); }&lt;/style&gt;") {
+		t.Errorf("got %q, want the color in the head of the page", got)
+	}
+}
+
+func TestIframeHead(t *testing.T) {
+	style := "<style>body { color: rgba(255, 0, 0, 1.00); }</style>"
+	for _, tc := range []struct {
+		page, want string
+	}{
+		{"<html><head></head><body>hi</body></html>", "<html><head>" + style + "</head>"},
+		{`<HTML lang="en"><HEAD id="h"><title>t</title></HEAD></html>`, `<HEAD id="h">` + style + "<title>"},
+		// A head is added where the page has none
+		{"<html><body><header>hi</header></body></html>", "<html><head>" + style + "</head><body><header>"},
+		{"<!DOCTYPE html><p>hi</p>", "<!DOCTYPE html><head>" + style + "</head><p>"},
+		{"<p>hi</p>", `srcdoc="` + escapeForSrcdoc("<head>"+style+"</head><p>hi</p>")},
+		// The page of a URL is left as it is
+		{"https://example.com", `src="https://example.com"`},
+	} {
+		got := blockHTML(EscapeIframe(tc.page, ""), 0, 0, ColorOption(color.RGBA{R: 255, A: 255}))
+		if want := escapeForSrcdoc(tc.want); !strings.Contains(got, want) && !strings.Contains(got, tc.want) {
+			t.Errorf("%s: got %q, want it to contain %q", tc.page, got, want)
+		}
+	}
+}
+
+func TestSpacingOptions(t *testing.T) {
+	got := blockHTML("<iframe></iframe>", 0, 0, MarginOption(8, 0, 8, 0), PaddingOption(1, 2, 3, 4))
+	want := "<div class='goterm-row' style='margin: 8px 0px 8px 0px;'><div style='width: 100%;padding: 1px 2px 3px 4px;box-sizing: border-box;overflow-x: auto;' class='goterm-box goterm-fill'>"
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("got %q, want it to start with %q", got, want)
+	}
+}
+
+func TestBorderOption(t *testing.T) {
+	got := blockHTML("<img>", 0, 0, BorderOption(1, color.Black), RadiusOption(8))
+	want := "<div class='goterm-row'><div style='border: 1px solid rgba(0, 0, 0, 1.00);border-radius: 8px;box-sizing: border-box;' class='goterm-box'><img></div></div>"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestAlignOption(t *testing.T) {
+	for _, tc := range []struct {
+		align Align
+		want  string
+	}{
+		{AlignCenter, "<div class='goterm-row'><div class='goterm-box'><p></p></div></div>"},
+		{AlignLeft, "<div class='goterm-row' style='justify-content: flex-start;'><div class='goterm-box'><p></p></div></div>"},
+		{AlignRight, "<div class='goterm-row' style='justify-content: flex-end;'><div class='goterm-box'><p></p></div></div>"},
+	} {
+		if got := blockHTML("<p></p>", 0, 0, AlignOption(tc.align)); got != tc.want {
+			t.Errorf("align %d: got %q, want %q", tc.align, got, tc.want)
+		}
+	}
+}
+
+func TestCaptioned(t *testing.T) {
+	first := Captioned(Image("a.png"), "<b>A</b>")
+	second := Captioned(Image("b.png"), "B")
+
+	got := first.HTML()
+	if !strings.Contains(got, `<div class='goterm-box'><img src="a.png"></div>`) {
+		t.Errorf("the element is not in a box: %q", got)
+	}
+	n1 := first.(*captioned).number
+	want := fmt.Sprintf("<figcaption class='goterm-caption'>Figure %d: &lt;b&gt;A&lt;/b&gt;</figcaption></figure>", n1)
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("got %q, want the suffix %q", got, want)
+	}
+	if n2 := second.(*captioned).number; n2 != n1+1 {
+		t.Errorf("figures are not numbered in order: %d, %d", n1, n2)
+	}
+}
+
+func TestTOC(t *testing.T) {
+	a, b := TOC().HTML(), TOC().HTML()
+	if !strings.HasPrefix(a, "<nav class='goterm-toc' id='goterm-toc-") || !strings.Contains(a, "<script>") {
+		t.Errorf("unexpected TOC: %q", a)
+	}
+	if a == b {
+		t.Error("the ids of two TOCs are the same")
+	}
+}
+
+func TestGzip(t *testing.T) {
+	tm := NewTerm()
+	tm.cacheOutput = true
+	tm.Open(Format(Custom))
+	fmt.Println("hi")
+	tm.Close()
+
+	for _, encoding := range []string{"gzip", "deflate, gzip;q=1.0", "gzip;q=0", ""} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", encoding)
+		w := httptest.NewRecorder()
+		tm.newServeMux(func() {}).ServeHTTP(w, r)
+
+		var body io.Reader = w.Body
+		compressed := w.Header().Get("Content-Encoding") == "gzip"
+		if want := encoding != "" && encoding != "gzip;q=0"; compressed != want {
+			t.Errorf("%q: got compressed %v, want %v", encoding, compressed, want)
+		}
+		if compressed {
+			gz, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body = gz
+		}
+		b, _ := io.ReadAll(body)
+		if !strings.Contains(string(b), preText("hi")) {
+			t.Errorf("%q: got %q, want it to contain the output", encoding, b)
+		}
+	}
+}
+
+func TestBlockPage(t *testing.T) {
+	page := BlockPage(Image("a.png"))
+	for _, want := range []string{"<!DOCTYPE html>", "div.goterm-row", "<img src=\"a.png\">", "</html>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("got %q, want it to contain %q", page, want)
+		}
+	}
+}
+
+func BenchmarkBufferWrite(b *testing.B) {
+	buf := NewBuffer()
+	done := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, buf)
+		close(done)
+	}()
+
+	p := []byte(strings.Repeat("x", 4096))
+	b.SetBytes(int64(len(p)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		buf.Write(p)
+	}
+	buf.Close()
+	<-done
+}
@@ -0,0 +1,82 @@
+package term
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// response is a response which is flushed for streaming, see gzipResponse.
+type response interface {
+	io.Writer
+	Flush()
+	Close() error
+}
+
+// throttledResponse flushes a response at most once per interval, so the content written between two flushes
+// is sent to the browser in one batch. A skipped flush is done later by a timer, so the content is never held
+// for longer than the interval.
+type throttledResponse struct {
+	mu       sync.Mutex
+	out      response
+	interval time.Duration
+	last     time.Time
+	timer    *time.Timer
+	closed   bool
+}
+
+// throttle returns a response which is flushed at most as often as the ThrottleLines option allows,
+// or out itself if the option is not set.
+func (t *Term) throttle(out response) response {
+	if t.throttleLines <= 0 {
+		return out
+	}
+	return &throttledResponse{out: out, interval: time.Second / time.Duration(t.throttleLines)}
+}
+
+func (r *throttledResponse) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.out.Write(p)
+}
+
+func (r *throttledResponse) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.timer != nil {
+		// A flush is already scheduled
+		return
+	}
+	wait := r.interval - time.Since(r.last)
+	if wait <= 0 {
+		r.flush()
+		return
+	}
+	r.timer = time.AfterFunc(wait, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.timer = nil
+		if !r.closed {
+			r.flush()
+		}
+	})
+}
+
+// flush flushes the response now. The caller must hold the lock.
+func (r *throttledResponse) flush() {
+	r.out.Flush()
+	r.last = time.Now()
+}
+
+// Close flushes the pending content and closes the response.
+func (r *throttledResponse) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+		r.flush()
+	}
+	return r.out.Close()
+}
@@ -0,0 +1,62 @@
+package term
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// tocScript adds a link to the nav element of the script for each heading after it, including the headings
+// which are printed later, since the output is streamed. The format argument is the id of the nav element.
+const tocScript = `<script>
+(function() {
+    const nav = document.getElementById('%s');
+    if (!nav) {
+        return;
+    }
+    const list = nav.querySelector('ul');
+    let count = list.children.length;
+
+    function update() {
+        document.querySelectorAll('.goterm-heading:not([data-goterm-toc])').forEach(function(h) {
+            if (!(nav.compareDocumentPosition(h) & Node.DOCUMENT_POSITION_FOLLOWING)) {
+                return;
+            }
+            // The mark is kept in a saved page, so its headings are not added again
+            h.setAttribute('data-goterm-toc', '');
+            if (!h.id) {
+                h.id = nav.id + '-' + (++count);
+            }
+            const a = document.createElement('a');
+            a.href = '#' + h.id;
+            a.textContent = h.textContent;
+            const li = document.createElement('li');
+            li.style.paddingLeft = (Number(h.tagName.substring(1)) - 1) + 'em';
+            li.appendChild(a);
+            list.appendChild(li);
+        });
+    }
+    update();
+    new MutationObserver(update).observe(document.body, { childList: true, subtree: true });
+})();
+</script>`
+
+// tocCount is the number of the last table of contents, which makes its id unique.
+var tocCount atomic.Int32
+
+type toc int
+
+// TOC returns a table of contents, which links to the headings printed after it, by the Heading and
+// the Section functions. It's built by the page as the headings appear, so it should be placed at the top
+// of a report. The headings in the page of an iframe are not included.
+func TOC() BlockElement {
+	return toc(tocCount.Add(1))
+}
+
+func (t toc) HTML() string {
+	id := fmt.Sprintf("goterm-toc-%d", t)
+	return fmt.Sprintf("<nav class='goterm-toc' id='%s'><ul></ul></nav>", id) + fmt.Sprintf(tocScript, id)
+}
+
+func (t toc) Options() []BlockOption {
+	return []BlockOption{fillOption}
+}
@@ -0,0 +1,209 @@
+package term
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// A minimal WebSocket server (RFC 6455) which is just enough to receive text messages from the browser.
+
+const (
+	wsGUID          = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxMessageLen = 1024 * 1024 // 1MB
+
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+
+	wsCloseProtocolError = 1002
+)
+
+var (
+	errWSMessageTooLarge = errors.New("websocket: message too large")
+	errWSUnmasked        = errors.New("websocket: unmasked client frame")
+)
+
+type wsConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// upgradeWebSocket performs the WebSocket handshake and takes over the connection.
+// A request from a page of another origin is rejected, so other sites can't connect to the local server.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if !sameOrigin(r) {
+		http.Error(w, "Forbidden origin", http.StatusForbidden)
+		return nil, errors.New("websocket: forbidden origin")
+	}
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		http.Error(w, "WebSocket upgrade required", http.StatusBadRequest)
+		return nil, errors.New("websocket: not a websocket handshake")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "Missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: missing key")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket unsupported!", http.StatusInternalServerError)
+		return nil, errors.New("websocket: hijacking unsupported")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha1.New()
+	h.Write([]byte(key + wsGUID))
+	accept := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + accept + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, rw: rw}, nil
+}
+
+// sameOrigin tells whether the Origin header is absent or matches the host of the request.
+// Browsers always send the header, so a missing one is a client other than a browser.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// ReadMessage returns the next text or binary message. Control frames are handled internally.
+// It returns io.EOF when the client closes the connection.
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err == errWSUnmasked {
+			var status [2]byte
+			binary.BigEndian.PutUint16(status[:], wsCloseProtocolError)
+			c.writeFrame(wsOpClose, status[:])
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case wsOpClose:
+			c.writeFrame(wsOpClose, nil)
+			return nil, io.EOF
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			msg = append(msg, payload...)
+			if len(msg) > wsMaxMessageLen {
+				return nil, errWSMessageTooLarge
+			}
+			if fin {
+				return msg, nil
+			}
+		default:
+			return nil, errors.New("websocket: unknown opcode")
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.rw, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	op = head[0] & 0x0f
+	if head[1]&0x80 == 0 {
+		// Client frames must be masked
+		err = errWSUnmasked
+		return
+	}
+	size := uint64(head[1] & 0x7f)
+
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.rw, ext[:]); err != nil {
+			return
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.rw, ext[:]); err != nil {
+			return
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if size > wsMaxMessageLen {
+		err = errWSMessageTooLarge
+		return
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.rw, mask[:]); err != nil {
+		return
+	}
+
+	payload = make([]byte, size)
+	if _, err = io.ReadFull(c.rw, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+func (c *wsConn) writeFrame(op byte, payload []byte) error {
+	// Server frames are not masked, and control frames are always small
+	var head []byte
+	size := len(payload)
+	switch {
+	case size < 126:
+		head = []byte{0x80 | op, byte(size)}
+	case size <= 0xffff:
+		head = []byte{0x80 | op, 126, 0, 0}
+		binary.BigEndian.PutUint16(head[2:], uint16(size))
+	default:
+		head = make([]byte, 10)
+		head[0], head[1] = 0x80|op, 127
+		binary.BigEndian.PutUint64(head[2:], uint64(size))
+	}
+	c.rw.Write(head)
+	c.rw.Write(payload)
+	return c.rw.Flush()
+}
+
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}